@@ -0,0 +1,154 @@
+package startstopper
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrClosed = errors.New("closed")
+	errClosed = NewErrorCode(ErrClosed, "STARTSTOPPER_ERR_CLOSED")
+
+	ErrKilled = errors.New("killed")
+	errKilled = NewErrorCode(ErrKilled, "STARTSTOPPER_ERR_KILLED")
+
+	ErrKillTimeout = errors.New("kill timeout")
+	errKillTimeout = NewErrorCode(ErrKillTimeout, "STARTSTOPPER_ERR_KILL_TIMEOUT")
+
+	ErrCompleted = errors.New("completed")
+	errCompleted = NewErrorCode(ErrCompleted, "STARTSTOPPER_ERR_COMPLETED")
+)
+
+// CloseWithCause like Close, cause is reported by context.Cause of graceful context.
+// nil cause means ErrClosed.
+// Wait for completion.
+func (startStopper *StartStopper) CloseWithCause(cause error) {
+	done := startStopper.Done()
+
+	if cause == nil {
+		cause = errClosed
+	}
+	startStopper.closeAsync(cause)
+
+	<-done
+}
+
+// KillWithCause like Kill, cause is reported by context.Cause of both contexts.
+// nil cause means ErrKilled.
+// Wait for completion.
+func (startStopper *StartStopper) KillWithCause(cause error) {
+	done := startStopper.Done()
+
+	if cause == nil {
+		cause = errKilled
+	}
+	startStopper.killAsync(cause)
+
+	<-done
+}
+
+// Cause returns why the run stopped (or is stopping), nil while running.
+// Kill context cause takes precedence:
+//   - ErrCompleted: cleanup is done without shutdown request
+//   - ErrClosed, ErrKilled or cause passed to CloseWithCause, KillWithCause
+//   - ErrKillTimeout joined with graceful cause: kill timer expired
+//   - cause of parent context
+func (startStopper *StartStopper) Cause() error {
+	return WithMutex1(&startStopper.mu, func() error {
+		if startStopper.done == alwaysClosedChan {
+			return startStopper.cause
+		}
+		return runCause(startStopper.gracefulCtx, startStopper.killCtx)
+	})
+}
+
+func runCause(gracefulCtx, killCtx context.Context) error {
+	if cause := context.Cause(killCtx); cause != nil {
+		return cause
+	}
+	return context.Cause(gracefulCtx)
+}
+
+func (startStopper *StartStopper) closeAsync(cause error) {
+	gracefulCtxCancelFunc, _, gen, ok := startStopper.cancelFuncs(cause, false)
+	if !ok {
+		return
+	}
+
+	gracefulCtxCancelFunc(cause)
+
+	// do not wait for AfterFunc, readiness must drop immediately
+	startStopper.setState(gen, StateStopping, StateStarting, StateRunning, StatePaused)
+}
+
+func (startStopper *StartStopper) killAsync(cause error) {
+	gracefulCtxCancelFunc, killCtxCancelFunc, gen, ok := startStopper.cancelFuncs(cause, true)
+	if !ok {
+		return
+	}
+
+	gracefulCtxCancelFunc(cause)
+	killCtxCancelFunc(cause)
+
+	startStopper.setState(gen, StateStopping, StateStarting, StateRunning, StatePaused)
+	startStopper.setState(gen, StateKilling, StateStarting, StateRunning, StatePaused, StateStopping)
+}
+
+// stopRequest of Close and Kill while startFn is running.
+type stopRequest struct {
+	closeCause error
+	killCause  error
+}
+
+// applyStop to the run which has been set up.
+func (startStopper *StartStopper) applyStop(stop stopRequest) {
+	if stop.closeCause != nil {
+		startStopper.closeAsync(stop.closeCause)
+	}
+	if stop.killCause != nil {
+		startStopper.killAsync(stop.killCause)
+	}
+}
+
+// cancelFuncs of the current (or last) run.
+// Before the first run cause is remembered to refuse the next Start, ok is false.
+// While startFn is running cause is remembered to stop the run once it is set up, ok is false.
+// During Restart cause is remembered to refuse the next run too.
+// Does not wait for startFn.
+func (startStopper *StartStopper) cancelFuncs(cause error, kill bool) (
+	gracefulCtxCancelFunc context.CancelCauseFunc,
+	killCtxCancelFunc context.CancelCauseFunc,
+	gen uint64,
+	ok bool,
+) {
+	WithMutex(&startStopper.cancelMu, func() {
+		if startStopper.restarting && startStopper.pendingCause == nil {
+			startStopper.pendingCause = cause
+		}
+
+		if startStopper.starting {
+			stop := &startStopper.startingStop
+			if kill && stop.killCause == nil {
+				stop.killCause = cause
+			}
+			if !kill && stop.closeCause == nil && stop.killCause == nil {
+				stop.closeCause = cause
+			}
+			return
+		}
+
+		if startStopper.gracefulCtxCancelFunc == nil {
+			if startStopper.pendingCause == nil {
+				startStopper.pendingCause = cause
+			}
+			return
+		}
+
+		gracefulCtxCancelFunc = startStopper.gracefulCtxCancelFunc
+		killCtxCancelFunc = startStopper.killCtxCancelFunc
+		gen = startStopper.generation()
+		ok = true
+	})
+
+	return gracefulCtxCancelFunc, killCtxCancelFunc, gen, ok
+}
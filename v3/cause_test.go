@@ -0,0 +1,136 @@
+package startstopper_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartStopper_Cause(t *testing.T) {
+	errMaintenance := errors.New("maintenance")
+
+	tests := []struct {
+		name      string
+		graceful  bool // worker stops on graceful context, otherwise on kill context
+		stop      func(startStopper *startstopper.StartStopper, cancelParent context.CancelFunc, doneFn func())
+		expected  []error
+		ctxCauses []error
+	}{
+		{
+			name:     "completed",
+			graceful: true,
+			stop: func(_ *startstopper.StartStopper, _ context.CancelFunc, doneFn func()) {
+				doneFn()
+			},
+			expected: []error{startstopper.ErrCompleted},
+		},
+		{
+			name:     "close",
+			graceful: true,
+			stop: func(startStopper *startstopper.StartStopper, _ context.CancelFunc, _ func()) {
+				startStopper.Close()
+			},
+			expected: []error{startstopper.ErrClosed},
+		},
+		{
+			name:     "close with cause",
+			graceful: true,
+			stop: func(startStopper *startstopper.StartStopper, _ context.CancelFunc, _ func()) {
+				startStopper.CloseWithCause(errMaintenance)
+			},
+			expected: []error{errMaintenance},
+		},
+		{
+			name: "kill",
+			stop: func(startStopper *startstopper.StartStopper, _ context.CancelFunc, _ func()) {
+				startStopper.Kill()
+			},
+			expected: []error{startstopper.ErrKilled},
+		},
+		{
+			name: "kill with cause",
+			stop: func(startStopper *startstopper.StartStopper, _ context.CancelFunc, _ func()) {
+				startStopper.KillWithCause(errMaintenance)
+			},
+			expected: []error{errMaintenance},
+		},
+		{
+			name: "kill timeout",
+			stop: func(startStopper *startstopper.StartStopper, _ context.CancelFunc, _ func()) {
+				startStopper.Close()
+			},
+			expected: []error{startstopper.ErrKillTimeout, startstopper.ErrClosed},
+		},
+		{
+			name:     "parent context",
+			graceful: true,
+			stop: func(_ *startstopper.StartStopper, cancelParent context.CancelFunc, _ func()) {
+				cancelParent()
+			},
+			expected: []error{context.Canceled},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			startStopper := startstopper.New(t.Context(), func(context.Context) time.Duration { return time.Millisecond })
+
+			parentCtx, cancelParent := context.WithCancel(t.Context())
+			defer cancelParent()
+
+			cleanupDone, closeFn := startstopper.ChanCloser(nil)
+			var once sync.Once
+			doneFn := func() { once.Do(closeFn) }
+
+			ctx, killCtx, done, err := startStopper.Start(parentCtx, cleanupDone, nil, nil)
+			require.NoError(t, err)
+			assert.NoError(t, startStopper.Cause())
+
+			stopCtx := killCtx
+			if tt.graceful {
+				stopCtx = ctx
+			}
+			go func() {
+				<-stopCtx.Done()
+				doneFn()
+			}()
+
+			// SUT
+			tt.stop(startStopper, cancelParent, doneFn)
+			<-done
+
+			for _, expected := range tt.expected {
+				assert.ErrorIs(t, startStopper.Cause(), expected)
+			}
+		})
+	}
+}
+
+func TestStartStopper_ContextCause(t *testing.T) {
+	startStopper := startstopper.New(t.Context(), nil)
+
+	cleanupDone, doneFn := startstopper.ChanCloser(nil)
+
+	ctx, killCtx, done, err := startStopper.Start(t.Context(), cleanupDone, nil, nil)
+	require.NoError(t, err)
+
+	go func() {
+		<-ctx.Done()
+		doneFn()
+	}()
+
+	// SUT
+	startStopper.Close()
+	<-done
+
+	require.ErrorIs(t, context.Cause(ctx), startstopper.ErrClosed)
+	require.True(t, startstopper.MatchErrorCodes(context.Cause(ctx), "STARTSTOPPER_ERR_CLOSED"))
+	require.NoError(t, killCtx.Err())
+}
@@ -33,3 +33,43 @@ func ChanCloserWaitGroup(done chan struct{}, delta int) (chan struct{}, func())
 
 	return done, wg.Done
 }
+
+// ErrCloser like ChanCloser, but reports error before closing.
+//
+//	done, doneFn := ErrCloser(nil)
+//	defer func() { doneFn(err) }()
+func ErrCloser(done chan error) (chan error, func(error)) {
+	if done == nil {
+		done = make(chan error, 1)
+	}
+
+	return done, func(err error) {
+		if err != nil {
+			done <- err
+		}
+		close(done)
+	}
+}
+
+// ErrCloserWaitGroup like ChanCloserWaitGroup, but each doneFn reports error.
+// done must be buffered for delta errors or drained concurrently (StartErr does).
+func ErrCloserWaitGroup(done chan error, delta int) (chan error, func(error)) {
+	if done == nil {
+		done = make(chan error, delta)
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(delta)
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	return done, func(err error) {
+		if err != nil {
+			done <- err
+		}
+		wg.Done()
+	}
+}
@@ -0,0 +1,57 @@
+package startstopper
+
+import (
+	"time"
+)
+
+// Clock runs timers of StartStopper: kill timer, shutdown phases, abandon timeout,
+// stuck watchdog, restart backoff and supervisor restart intensity.
+// See startstoppertest.FakeClock for tests.
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls f after d.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer ...
+type Timer interface {
+	// Stop prevents the timer from firing, false if it has already fired or been stopped.
+	Stop() bool
+}
+
+// SystemClock is the default Clock, uses package time.
+type SystemClock struct{}
+
+// Now ...
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// AfterFunc ...
+func (SystemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// SetClock replaces clock of timers, nil means SystemClock.
+// Takes effect on next Start, Transition.At is stamped by the new clock immediately.
+func (startStopper *StartStopper) SetClock(clock Clock) {
+	if clock == nil {
+		clock = SystemClock{}
+	}
+
+	WithMutex(&startStopper.mu, func() {
+		startStopper.clock = clock
+		WithMutex(&startStopper.stateMu, func() {
+			startStopper.stateClock = clock
+		})
+	})
+}
+
+func (startStopper *StartStopper) getClock() Clock {
+	return WithMutex1(&startStopper.mu, func() Clock {
+		if startStopper.clock == nil {
+			return SystemClock{}
+		}
+		return startStopper.clock
+	})
+}
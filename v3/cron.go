@@ -0,0 +1,217 @@
+package startstopper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule of Periodic.
+type Schedule interface {
+	// Next returns the first time after t, zero time if there is none.
+	Next(t time.Time) time.Time
+}
+
+// Every returns schedule with fixed interval.
+// Ticks are counted from the previous tick, not from its handling, so they do not drift.
+func Every(interval time.Duration) Schedule {
+	return everySchedule{interval: interval}
+}
+
+type everySchedule struct {
+	interval time.Duration
+}
+
+// Next ...
+func (schedule everySchedule) Next(t time.Time) time.Time {
+	return t.Add(schedule.interval)
+}
+
+// cronSchedule matches minutes of t.Location().
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64 // bit sets
+
+	// day matches if dom or dow matches, unless one of them is *
+	domAny, dowAny bool
+}
+
+type cronField struct {
+	min, max int
+	names    []string // names[i] is value min+i
+}
+
+var (
+	cronMinute = cronField{min: 0, max: 59}
+	cronHour   = cronField{min: 0, max: 23}
+	cronDom    = cronField{min: 1, max: 31}
+	cronMonth  = cronField{min: 1, max: 12, names: []string{
+		"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec",
+	}}
+	cronDow = cronField{min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+
+	cronMacros = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// ParseCron parses standard 5 fields cron expression: minute hour day-of-month month day-of-week.
+// Fields support *, values, ranges a-b, steps */n and a-b/n, lists, month and day names.
+// Day of week 7 is Sunday. Macros @yearly, @monthly, @weekly, @daily and @hourly are supported.
+// Times are matched in location of the Periodic clock.
+// Returns ErrInvalidOption if expr is invalid.
+func ParseCron(expr string) (Schedule, error) {
+	if macro, ok := cronMacros[strings.ToLower(strings.TrimSpace(expr))]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, JoinErrors(errInvalidOption, fmt.Errorf("cron %q: expected 5 fields, got %d", expr, len(fields)))
+	}
+
+	var (
+		schedule cronSchedule
+		err      error
+	)
+	parse := func(i int, field cronField) uint64 {
+		if err != nil {
+			return 0
+		}
+
+		var bits uint64
+		bits, err = field.parse(fields[i])
+		if err != nil {
+			err = JoinErrors(errInvalidOption, fmt.Errorf("cron %q: field %d: %w", expr, i+1, err))
+		}
+		return bits
+	}
+
+	schedule.minute = parse(0, cronMinute)
+	schedule.hour = parse(1, cronHour)
+	schedule.dom = parse(2, cronDom)
+	schedule.month = parse(3, cronMonth)
+	schedule.dow = parse(4, cronDow)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sunday is both 0 and 7
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+
+	schedule.domAny = fields[2] == "*"
+	schedule.dowAny = fields[4] == "*"
+
+	return &schedule, nil
+}
+
+func (field cronField) parse(s string) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(s, ",") {
+		lo, hi, step := field.min, field.max, 1
+
+		rng := part
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			rng, step = part[:i], n
+		}
+
+		if rng != "*" {
+			var err error
+			if i := strings.IndexByte(rng, '-'); i >= 0 {
+				lo, err = field.value(rng[:i])
+				if err == nil {
+					hi, err = field.value(rng[i+1:])
+				}
+			} else {
+				lo, err = field.value(rng)
+				if step == 1 {
+					hi = lo
+				}
+			}
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		if lo > hi {
+			return 0, fmt.Errorf("invalid range %q", part)
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+
+	return bits, nil
+}
+
+func (field cronField) value(s string) (int, error) {
+	for i, name := range field.names {
+		if strings.EqualFold(s, name) {
+			return field.min + i, nil
+		}
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil || v < field.min || v > field.max {
+		return 0, fmt.Errorf("value %q is out of [%d, %d]", s, field.min, field.max)
+	}
+	return v, nil
+}
+
+// Next ...
+func (schedule *cronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	// no match within 5 years means never, e.g. Feb 30
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case schedule.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+
+		case !schedule.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+
+		case schedule.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+
+		case schedule.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func (schedule *cronSchedule) matchDay(t time.Time) bool {
+	dom := schedule.dom&(1<<uint(t.Day())) != 0
+	dow := schedule.dow&(1<<uint(t.Weekday())) != 0
+
+	switch {
+	case schedule.domAny:
+		return dow
+	case schedule.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
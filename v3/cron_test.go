@@ -0,0 +1,72 @@
+package startstopper_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron(t *testing.T) {
+	// Wednesday
+	from := time.Date(2025, time.January, 15, 10, 30, 45, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		next []string
+	}{
+		{expr: "* * * * *", next: []string{"2025-01-15 10:31", "2025-01-15 10:32"}},
+		{expr: "*/15 * * * *", next: []string{"2025-01-15 10:45", "2025-01-15 11:00"}},
+		{expr: "0 9-17/4 * * *", next: []string{"2025-01-15 13:00", "2025-01-15 17:00", "2025-01-16 09:00"}},
+		{expr: "30 8 * * mon-fri", next: []string{"2025-01-16 08:30", "2025-01-17 08:30", "2025-01-20 08:30"}},
+		{expr: "0 0 * * 7", next: []string{"2025-01-19 00:00", "2025-01-26 00:00"}},
+		{expr: "0 0 1,15 * sun", next: []string{"2025-01-19 00:00", "2025-01-26 00:00", "2025-02-01 00:00"}},
+		{expr: "0 12 29 feb *", next: []string{"2028-02-29 12:00", "2032-02-29 12:00"}},
+		{expr: "@monthly", next: []string{"2025-02-01 00:00", "2025-03-01 00:00"}},
+		{expr: "@hourly", next: []string{"2025-01-15 11:00", "2025-01-15 12:00"}},
+		{expr: "0 0 30 feb *", next: []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			// SUT
+			schedule, err := startstopper.ParseCron(tt.expr)
+			require.NoError(t, err)
+
+			var next []string
+			at := from
+			for range tt.next {
+				at = schedule.Next(at)
+				if at.IsZero() {
+					next = append(next, "")
+					continue
+				}
+				next = append(next, at.Format("2006-01-02 15:04"))
+			}
+			assert.Equal(t, tt.next, next)
+		})
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * foo *",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			// SUT
+			_, err := startstopper.ParseCron(expr)
+			assert.ErrorIs(t, err, startstopper.ErrInvalidOption)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package startstopper
 
 import (
+	"errors"
 	"slices"
 )
 
@@ -45,8 +46,6 @@ func (e *errorCode) As(target any) bool {
 	default:
 		return false
 	}
-
-	return true
 }
 
 // ErrorCodeMatcher ...
@@ -79,10 +78,78 @@ func MatchErrorCodes(target any, errCodes ...string) bool {
 	}
 
 	if t, ok := target.(interface{ ErrorCodes() []string }); ok {
-		slices.ContainsFunc(t.ErrorCodes(), func(errCode string) bool {
+		return slices.ContainsFunc(t.ErrorCodes(), func(errCode string) bool {
 			return slices.Contains(errCodes, errCode)
 		})
 	}
 
 	return false
 }
+
+// JoinErrors like errors.Join, but the result reports ErrorCodes of all joined errors.
+func JoinErrors(errs ...error) error {
+	var joined joinedErrors
+	for _, err := range errs {
+		if err != nil {
+			joined.errs = append(joined.errs, err)
+		}
+	}
+
+	if len(joined.errs) == 0 {
+		return nil
+	}
+
+	return &joined
+}
+
+type joinedErrors struct {
+	errs []error
+}
+
+// Error ...
+func (e *joinedErrors) Error() string {
+	return errors.Join(e.errs...).Error()
+}
+
+// Unwrap ...
+func (e *joinedErrors) Unwrap() []error {
+	return e.errs
+}
+
+// ErrorCodes of all wrapped errors.
+func (e *joinedErrors) ErrorCodes() []string {
+	var codes []string
+	for _, err := range e.errs {
+		codes = appendErrorCodes(codes, err)
+	}
+	return codes
+}
+
+func appendErrorCodes(codes []string, err error) []string {
+	if t, ok := err.(interface{ ErrorCode() string }); ok && !slices.Contains(codes, t.ErrorCode()) {
+		codes = append(codes, t.ErrorCode())
+	}
+
+	if t, ok := err.(interface{ ErrorCodes() []string }); ok {
+		for _, code := range t.ErrorCodes() {
+			if !slices.Contains(codes, code) {
+				codes = append(codes, code)
+			}
+		}
+		return codes
+	}
+
+	switch t := err.(type) {
+	case interface{ Unwrap() error }:
+		if inner := t.Unwrap(); inner != nil {
+			codes = appendErrorCodes(codes, inner)
+		}
+
+	case interface{ Unwrap() []error }:
+		for _, inner := range t.Unwrap() {
+			codes = appendErrorCodes(codes, inner)
+		}
+	}
+
+	return codes
+}
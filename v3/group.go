@@ -0,0 +1,371 @@
+package startstopper
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrDependencyCycle = errors.New("dependency cycle")
+	errDependencyCycle = NewErrorCode(ErrDependencyCycle, "STARTSTOPPER_ERR_DEPENDENCY_CYCLE")
+
+	ErrUnknownDependency = errors.New("unknown dependency")
+	errUnknownDependency = NewErrorCode(ErrUnknownDependency, "STARTSTOPPER_ERR_UNKNOWN_DEPENDENCY")
+)
+
+// GroupMember ...
+type GroupMember struct {
+	Name      string
+	Service   Service
+	DependsOn []string // names of members to be ready before this one starts
+}
+
+// GroupSpec ...
+type GroupSpec struct {
+	Concurrency         int // max members starting at once, zero means unlimited
+	KillTimeoutProvider func(ctx context.Context) time.Duration
+}
+
+// Group starts members in dependency order and stops them in reverse dependency order.
+// Member is closed only after all members depending on it are done.
+// Group is a Service.
+type Group struct {
+	StartStopper
+
+	spec     GroupSpec
+	members  []*groupMember
+	graphErr error
+}
+
+type groupMember struct {
+	GroupMember
+
+	deps       []*groupMember
+	dependents []*groupMember
+}
+
+// member state of a single Group run
+type memberRun struct {
+	*groupMember
+
+	ready   chan struct{} // closes when started or failed to start
+	started bool          // Start called, valid after ready
+	err     error         // start error, valid after ready
+
+	exited  chan struct{} // closes when Start returned and Done
+	exitErr error         // valid after exited
+}
+
+// NewGroup ...
+// Unknown dependencies and cycles are reported by Start.
+func NewGroup(
+	ctx context.Context,
+	spec GroupSpec,
+	members ...GroupMember,
+) *Group {
+	group := &Group{spec: spec}
+
+	byName := make(map[string]*groupMember, len(members))
+	for _, member := range members {
+		m := &groupMember{GroupMember: member}
+		group.members = append(group.members, m)
+		byName[m.Name] = m
+	}
+
+link:
+	for _, m := range group.members {
+		for _, name := range m.DependsOn {
+			dep, ok := byName[name]
+			if !ok {
+				group.graphErr = JoinErrors(errUnknownDependency, errors.New(m.Name+" depends on "+name))
+				break link
+			}
+			m.deps = append(m.deps, dep)
+			dep.dependents = append(dep.dependents, m)
+		}
+	}
+
+	if group.graphErr == nil {
+		group.graphErr = group.checkCycles()
+	}
+
+	_ = group.StartStopper.Init(ctx, spec.KillTimeoutProvider)
+
+	return group
+}
+
+// checkCycles with Kahn's algorithm.
+func (group *Group) checkCycles() error {
+	pending := make(map[*groupMember]int, len(group.members))
+	var queue []*groupMember
+
+	for _, m := range group.members {
+		pending[m] = len(m.deps)
+		if len(m.deps) == 0 {
+			queue = append(queue, m)
+		}
+	}
+
+	resolved := 0
+	for len(queue) > 0 {
+		m := queue[0]
+		queue = queue[1:]
+		resolved++
+
+		for _, dependent := range m.dependents {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				queue = append(queue, dependent)
+			}
+		}
+	}
+
+	if resolved != len(group.members) {
+		return errDependencyCycle
+	}
+
+	return nil
+}
+
+// Start members in dependency order, independent members in parallel.
+// Fails fast: if a member fails to start, started members are stopped and error is returned.
+// readyCh is notified once all members are started.
+// Blocks until all members are stopped. Returns joined errors of members.
+func (group *Group) Start(ctx context.Context, readyCh chan<- error) error {
+	err := group.StartStopper.InitNotify(ctx, readyCh, nil)
+	if err != nil {
+		return err
+	}
+
+	if group.graphErr != nil {
+		Notify(readyCh, group.graphErr, NotifyCloseModeAlways)
+		return group.graphErr
+	}
+
+	cleanupErr, doneFn := ErrCloser(nil)
+
+	ctx, killCtx, done, err := group.StartStopper.StartErr(ctx, cleanupErr, nil, nil)
+	if err != nil {
+		Notify(readyCh, err, NotifyCloseModeAlways)
+		return err
+	}
+
+	err = group.run(ctx, killCtx, readyCh)
+
+	doneFn(err)
+	<-done
+
+	return err
+}
+
+func (group *Group) run(
+	ctx context.Context,
+	killCtx context.Context,
+	readyCh chan<- error,
+) error {
+	// members are stopped explicitly, in reverse dependency order
+	childCtx := context.WithoutCancel(ctx)
+
+	startCtx, cancelStart := context.WithCancel(ctx)
+	defer cancelStart()
+
+	runs := make(map[*groupMember]*memberRun, len(group.members))
+	for _, m := range group.members {
+		runs[m] = &memberRun{
+			groupMember: m,
+			ready:       make(chan struct{}),
+			exited:      make(chan struct{}),
+		}
+	}
+
+	var sem chan struct{}
+	if group.spec.Concurrency > 0 {
+		sem = make(chan struct{}, group.spec.Concurrency)
+	}
+
+	var wg sync.WaitGroup
+	for _, m := range group.members {
+		wg.Add(1)
+		go func(r *memberRun) {
+			defer wg.Done()
+
+			group.startMember(startCtx, killCtx, childCtx, sem, r, runs)
+			if r.err != nil {
+				// fail fast
+				cancelStart()
+			}
+		}(runs[m])
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, m := range group.members {
+		r := runs[m]
+		if !r.started {
+			close(r.exited)
+			continue
+		}
+		if r.err != nil {
+			errs = append(errs, r.err)
+		}
+	}
+
+	if err := JoinErrors(errs...); err != nil || ctx.Err() != nil {
+		group.stop(killCtx, runs)
+
+		if err == nil {
+			err = ctx.Err()
+		}
+		Notify(readyCh, err, NotifyCloseModeAlways)
+		return err
+	}
+
+	Notify(readyCh, nil, NotifyCloseModeAlways)
+
+	allExited := make(chan struct{})
+	go func() {
+		for _, r := range runs {
+			<-r.exited
+		}
+		close(allExited)
+	}()
+
+	select {
+	case <-ctx.Done():
+	case <-allExited:
+	}
+
+	group.stop(killCtx, runs)
+
+	for _, m := range group.members {
+		errs = append(errs, runs[m].exitErr)
+	}
+
+	return JoinErrors(errs...)
+}
+
+func (group *Group) startMember(
+	startCtx context.Context,
+	killCtx context.Context,
+	childCtx context.Context,
+	sem chan struct{},
+	r *memberRun,
+	runs map[*groupMember]*memberRun,
+) {
+	defer close(r.ready)
+
+	for _, dep := range r.deps {
+		select {
+		case <-runs[dep].ready:
+			if runs[dep].err != nil || !runs[dep].started {
+				return
+			}
+
+		case <-startCtx.Done():
+			return
+		}
+	}
+
+	if sem != nil {
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+
+		case <-startCtx.Done():
+			return
+		}
+	}
+
+	if startCtx.Err() != nil {
+		return
+	}
+
+	r.started = true
+	memberReadyCh := make(chan error, 1)
+
+	go func() {
+		err := r.Service.Start(childCtx, memberReadyCh)
+		<-r.Service.Done()
+
+		r.exitErr = err
+		close(r.exited)
+	}()
+
+	// childCtx is not canceled, pass shutdown to the starting member
+	startDone := startCtx.Done()
+	killDone := killCtx.Done()
+
+	for {
+		select {
+		case r.err = <-memberReadyCh:
+			return
+
+		case <-r.exited:
+			select {
+			case r.err = <-memberReadyCh:
+				// ready and exited at once
+				return
+			default:
+			}
+
+			r.err = r.exitErr
+			if r.err == nil {
+				// exited before ready, take it as failed start
+				r.err = errStart
+			}
+			return
+
+		case <-startDone:
+			startDone = nil
+			r.Service.CloseAsync()
+
+		case <-killDone:
+			killDone = nil
+			r.Service.KillAsync()
+		}
+	}
+}
+
+// stop members in reverse dependency order, kill all of them when killCtx is done.
+func (group *Group) stop(killCtx context.Context, runs map[*groupMember]*memberRun) {
+	stopped := make(chan struct{})
+
+	go func() {
+		select {
+		case <-killCtx.Done():
+			for _, r := range runs {
+				if r.started {
+					r.Service.KillAsync()
+				}
+			}
+
+		case <-stopped:
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for _, r := range runs {
+		wg.Add(1)
+		go func(r *memberRun) {
+			defer wg.Done()
+
+			for _, dependent := range r.dependents {
+				<-runs[dependent].exited
+			}
+
+			select {
+			case <-r.exited:
+				return
+			default:
+			}
+
+			r.Service.CloseAsync()
+			<-r.exited
+		}(r)
+	}
+	wg.Wait()
+
+	close(stopped)
+}
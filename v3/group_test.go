@@ -0,0 +1,256 @@
+package startstopper_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type eventLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (log *eventLog) add(event string) {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+
+	log.events = append(log.events, event)
+}
+
+func (log *eventLog) index(event string) int {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+
+	for i, e := range log.events {
+		if e == event {
+			return i
+		}
+	}
+	return -1
+}
+
+func newLoggedChild(t *testing.T, log *eventLog, name string) *testChild {
+	child := newTestChild(t.Context())
+	child.startFn = func() error {
+		log.add("start " + name)
+		return nil
+	}
+	child.stopFn = func() {
+		log.add("stop " + name)
+	}
+	return child
+}
+
+// startingChild is ready once started is closed, fails to start when its run is stopped before.
+type startingChild struct {
+	startstopper.StartStopper
+
+	started chan struct{}
+	kill    bool // ignore graceful shutdown
+}
+
+func (child *startingChild) Start(ctx context.Context, readyCh chan<- error) error {
+	err := child.StartStopper.InitNotify(ctx, readyCh, nil)
+	if err != nil {
+		return err
+	}
+
+	ctx, killCtx, done, err := child.StartStopper.StartWorkers(ctx, nil, nil, func(ctx context.Context, _ context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	if err != nil {
+		startstopper.Notify(readyCh, err, startstopper.NotifyCloseModeAlways)
+		return err
+	}
+
+	stop := ctx
+	if child.kill {
+		stop = killCtx
+	}
+
+	select {
+	case <-child.started:
+		startstopper.Notify(readyCh, nil, startstopper.NotifyCloseModeAlways)
+	case <-stop.Done():
+		startstopper.Notify(readyCh, context.Cause(stop), startstopper.NotifyCloseModeAlways)
+	}
+
+	<-done
+	return child.Err()
+}
+
+func TestGroup_Order(t *testing.T) {
+	log := &eventLog{}
+
+	group := startstopper.NewGroup(
+		t.Context(),
+		startstopper.GroupSpec{},
+		startstopper.GroupMember{Name: "api", Service: newLoggedChild(t, log, "api"), DependsOn: []string{"cache", "db"}},
+		startstopper.GroupMember{Name: "cache", Service: newLoggedChild(t, log, "cache"), DependsOn: []string{"db"}},
+		startstopper.GroupMember{Name: "db", Service: newLoggedChild(t, log, "db")},
+		startstopper.GroupMember{Name: "metrics", Service: newLoggedChild(t, log, "metrics")},
+	)
+
+	// SUT
+	errCh := startService(t, group)
+
+	assert.Less(t, log.index("start db"), log.index("start cache"))
+	assert.Less(t, log.index("start cache"), log.index("start api"))
+	assert.NotEqual(t, -1, log.index("start metrics"))
+
+	group.Close()
+	require.NoError(t, <-errCh)
+
+	assert.Less(t, log.index("stop api"), log.index("stop cache"))
+	assert.Less(t, log.index("stop cache"), log.index("stop db"))
+	assert.NotEqual(t, -1, log.index("stop metrics"))
+}
+
+func TestGroup_Errors(t *testing.T) {
+	t.Run("cycle", func(t *testing.T) {
+		a := newTestChild(t.Context())
+		b := newTestChild(t.Context())
+
+		group := startstopper.NewGroup(
+			t.Context(),
+			startstopper.GroupSpec{},
+			startstopper.GroupMember{Name: "a", Service: a, DependsOn: []string{"b"}},
+			startstopper.GroupMember{Name: "b", Service: b, DependsOn: []string{"a"}},
+		)
+
+		readyCh := make(chan error, 1)
+
+		// SUT
+		err := group.Start(t.Context(), readyCh)
+		require.ErrorIs(t, err, startstopper.ErrDependencyCycle)
+		require.ErrorIs(t, <-readyCh, startstopper.ErrDependencyCycle)
+		assert.Equal(t, []int32{0, 0}, startCounts(a, b)())
+	})
+
+	t.Run("unknown dependency", func(t *testing.T) {
+		group := startstopper.NewGroup(
+			t.Context(),
+			startstopper.GroupSpec{},
+			startstopper.GroupMember{Name: "a", Service: newTestChild(t.Context()), DependsOn: []string{"b", "c"}},
+		)
+
+		// SUT
+		err := group.Start(t.Context(), nil)
+		require.ErrorIs(t, err, startstopper.ErrUnknownDependency)
+		assert.True(t, startstopper.MatchErrorCodes(err, "STARTSTOPPER_ERR_UNKNOWN_DEPENDENCY"))
+		assert.ErrorContains(t, err, "a depends on b")
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		log := &eventLog{}
+
+		db := newLoggedChild(t, log, "db")
+		cache := newTestChild(t.Context())
+		cache.startFn = func() error { return errChild }
+		api := newLoggedChild(t, log, "api")
+
+		group := startstopper.NewGroup(
+			t.Context(),
+			startstopper.GroupSpec{},
+			startstopper.GroupMember{Name: "db", Service: db},
+			startstopper.GroupMember{Name: "cache", Service: cache, DependsOn: []string{"db"}},
+			startstopper.GroupMember{Name: "api", Service: api, DependsOn: []string{"cache"}},
+		)
+
+		readyCh := make(chan error, 1)
+
+		// SUT
+		err := group.Start(t.Context(), readyCh)
+		require.ErrorIs(t, err, errChild)
+		require.ErrorIs(t, <-readyCh, errChild)
+
+		assert.NotEqual(t, -1, log.index("stop db"))
+		assert.Equal(t, int32(0), api.starts.Load())
+	})
+}
+
+func TestGroup_Concurrency(t *testing.T) {
+	var active, maxActive atomic.Int32
+
+	members := make([]startstopper.GroupMember, 0, 6)
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		child := newTestChild(t.Context())
+		child.startFn = func() error {
+			n := active.Add(1)
+			defer active.Add(-1)
+
+			for {
+				m := maxActive.Load()
+				if n <= m || maxActive.CompareAndSwap(m, n) {
+					break
+				}
+			}
+
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		}
+		members = append(members, startstopper.GroupMember{Name: name, Service: child})
+	}
+
+	group := startstopper.NewGroup(t.Context(), startstopper.GroupSpec{Concurrency: 2}, members...)
+
+	// SUT
+	errCh := startService(t, group)
+	assert.LessOrEqual(t, maxActive.Load(), int32(2))
+
+	group.Close()
+	require.NoError(t, <-errCh)
+}
+
+func TestGroup_StopWhileStarting(t *testing.T) {
+	tests := []struct {
+		name  string
+		kill  bool
+		cause error
+	}{
+		{name: "close", cause: startstopper.ErrClosed},
+		{name: "kill", kill: true, cause: startstopper.ErrKilled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestChild(t.Context())
+			cache := &startingChild{started: make(chan struct{}), kill: tt.kill}
+			_ = cache.Init(t.Context(), func(context.Context) time.Duration { return time.Minute })
+
+			group := startstopper.NewGroup(
+				t.Context(),
+				startstopper.GroupSpec{KillTimeoutProvider: func(context.Context) time.Duration { return time.Minute }},
+				startstopper.GroupMember{Name: "db", Service: db},
+				startstopper.GroupMember{Name: "cache", Service: cache, DependsOn: []string{"db"}},
+			)
+
+			readyCh := make(chan error, 1)
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- group.Start(t.Context(), readyCh)
+			}()
+			assert.Eventually(t, func() bool { return cache.State() == startstopper.StateRunning }, time.Second, time.Millisecond)
+
+			// SUT
+			if tt.kill {
+				group.KillAsync()
+			} else {
+				group.CloseAsync()
+			}
+
+			require.ErrorIs(t, <-readyCh, tt.cause)
+			require.ErrorIs(t, <-errCh, tt.cause)
+			assert.ErrorIs(t, cache.Cause(), tt.cause)
+			assert.Equal(t, startstopper.StateStopped, db.State())
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package startstopper
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// HTTPServer runs http.Server.
+// Graceful shutdown calls Server.Shutdown, kill calls Server.Close.
+// Request contexts are derived from kill context unless Server.BaseContext is set.
+// http.Server can not be served again after shutdown, so HTTPServer can be started once,
+// next Start fails with ErrStart.
+// HTTPServer is a Service.
+type HTTPServer struct {
+	StartStopper
+
+	Server *http.Server
+
+	listener atomic.Pointer[net.Listener]
+	inFlight atomic.Int64
+	started  atomic.Bool // Server was served, it can not be served again
+}
+
+// NewHTTPServer ...
+func NewHTTPServer(
+	ctx context.Context,
+	server *http.Server,
+	killTimeoutProvider func(ctx context.Context) time.Duration,
+) *HTTPServer {
+	srv := &HTTPServer{Server: server}
+
+	if server.BaseContext == nil {
+		server.BaseContext = func(net.Listener) context.Context {
+			return srv.KillContext()
+		}
+	}
+
+	_ = srv.StartStopper.Init(ctx, killTimeoutProvider)
+
+	return srv
+}
+
+// Start listens on Server.Addr (":http" if empty) and serves.
+// readyCh is notified once listening, see Addr.
+// Blocks until the run is done. Returns Err.
+func (srv *HTTPServer) Start(ctx context.Context, readyCh chan<- error) error {
+	err := srv.StartStopper.InitNotify(ctx, readyCh, nil)
+	if err != nil {
+		return err
+	}
+
+	_, _, done, err := srv.StartStopper.StartWorkers(ctx, readyCh, srv.listen, srv.serve, srv.shutdown)
+	if err != nil {
+		return err
+	}
+
+	<-done
+
+	return srv.Err()
+}
+
+// Addr returns the bound address, nil before listening.
+// Useful with ":0".
+func (srv *HTTPServer) Addr() net.Addr {
+	listener := srv.listener.Load()
+	if listener == nil {
+		return nil
+	}
+	return (*listener).Addr()
+}
+
+// InFlight returns number of requests served by Middleware.
+func (srv *HTTPServer) InFlight() int {
+	return int(srv.inFlight.Load())
+}
+
+// Middleware tracks in-flight requests, see InFlight.
+// Once graceful shutdown begins new requests get 503 with Connection: close.
+func (srv *HTTPServer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if srv.Context().Err() != nil {
+			w.Header().Set("Connection", "close")
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+
+		srv.inFlight.Add(1)
+		defer srv.inFlight.Add(-1)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (srv *HTTPServer) listen() error {
+	if srv.started.Load() {
+		return errStart
+	}
+
+	addr := srv.Server.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	srv.listener.Store(&listener)
+	srv.started.Store(true)
+	return nil
+}
+
+func (srv *HTTPServer) serve(_ context.Context, _ context.Context) error {
+	err := srv.Server.Serve(*srv.listener.Load())
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	// shutdown worker waits for graceful shutdown
+	srv.closeAsync(err)
+	return err
+}
+
+func (srv *HTTPServer) shutdown(ctx context.Context, killCtx context.Context) error {
+	<-ctx.Done()
+
+	err := srv.Server.Shutdown(killCtx)
+	if killCtx.Err() != nil {
+		// killed while draining
+		return srv.Server.Close()
+	}
+	return err
+}
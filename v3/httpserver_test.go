@@ -0,0 +1,170 @@
+package startstopper_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func get(t *testing.T, addr net.Addr) (int, string) {
+	t.Helper()
+
+	resp, err := http.Get("http://" + addr.String())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestHTTPServer(t *testing.T) {
+	t.Run("serves on bound address", func(t *testing.T) {
+		server := &http.Server{
+			Addr: "127.0.0.1:0",
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = io.WriteString(w, "ok")
+			}),
+		}
+		srv := startstopper.NewHTTPServer(t.Context(), server, nil)
+		assert.Nil(t, srv.Addr())
+
+		// SUT
+		result := startService(t, srv)
+
+		addr := srv.Addr()
+		require.NotNil(t, addr)
+		assert.NotEqual(t, 0, addr.(*net.TCPAddr).Port)
+
+		code, body := get(t, addr)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "ok", body)
+
+		srv.Close()
+		require.NoError(t, <-result)
+		assert.ErrorIs(t, srv.Cause(), startstopper.ErrClosed)
+
+		_, err := net.Dial("tcp", addr.String())
+		assert.Error(t, err)
+	})
+
+	t.Run("starts once", func(t *testing.T) {
+		server := &http.Server{Addr: "127.0.0.1:0"}
+		srv := startstopper.NewHTTPServer(t.Context(), server, nil)
+
+		result := startService(t, srv)
+		addr := srv.Addr()
+		srv.Close()
+		require.NoError(t, <-result)
+
+		// SUT
+		readyCh := make(chan error, 1)
+		err := srv.Start(t.Context(), readyCh)
+
+		require.ErrorIs(t, err, startstopper.ErrStart)
+		require.ErrorIs(t, <-readyCh, startstopper.ErrStart)
+		assert.Equal(t, addr, srv.Addr())
+		assert.Equal(t, startstopper.StateStopped, srv.State())
+	})
+
+	t.Run("in-flight request finishes on graceful shutdown", func(t *testing.T) {
+		started := make(chan struct{})
+		gate := make(chan struct{})
+
+		server := &http.Server{Addr: "127.0.0.1:0"}
+		srv := startstopper.NewHTTPServer(t.Context(), server, func(context.Context) time.Duration { return time.Minute })
+		handler := srv.Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			close(started)
+			<-gate
+			_, _ = io.WriteString(w, "done")
+		}))
+		server.Handler = handler
+
+		result := startService(t, srv)
+
+		type response struct {
+			code int
+			body string
+		}
+		responses := make(chan response, 1)
+		go func() {
+			code, body := get(t, srv.Addr())
+			responses <- response{code, body}
+		}()
+		<-started
+		assert.Equal(t, 1, srv.InFlight())
+
+		// SUT
+		srv.CloseAsync()
+
+		// new requests are refused while draining
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, "close", rec.Header().Get("Connection"))
+
+		close(gate)
+		assert.Equal(t, response{http.StatusOK, "done"}, <-responses)
+
+		require.NoError(t, <-result)
+		assert.Zero(t, srv.InFlight())
+	})
+
+	t.Run("kill cancels requests", func(t *testing.T) {
+		started := make(chan struct{})
+		causes := make(chan error, 1)
+
+		server := &http.Server{
+			Addr: "127.0.0.1:0",
+			Handler: http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				close(started)
+				// ignore graceful shutdown
+				<-r.Context().Done()
+				causes <- context.Cause(r.Context())
+			}),
+		}
+		srv := startstopper.NewHTTPServer(t.Context(), server, func(context.Context) time.Duration { return time.Millisecond })
+
+		result := startService(t, srv)
+
+		go func() {
+			resp, err := http.Get("http://" + srv.Addr().String())
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+		}()
+		<-started
+
+		// SUT
+		srv.CloseAsync()
+
+		require.NoError(t, <-result)
+		assert.ErrorIs(t, srv.Cause(), startstopper.ErrKillTimeout)
+		assert.ErrorIs(t, <-causes, startstopper.ErrKillTimeout)
+	})
+
+	t.Run("listen error", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = listener.Close() })
+
+		server := &http.Server{Addr: listener.Addr().String()}
+		srv := startstopper.NewHTTPServer(t.Context(), server, nil)
+
+		// SUT
+		readyCh := make(chan error, 1)
+		err = srv.Start(t.Context(), readyCh)
+
+		require.Error(t, err)
+		require.ErrorIs(t, <-readyCh, err)
+		assert.Equal(t, startstopper.StateStopped, srv.State())
+	})
+}
@@ -0,0 +1,83 @@
+package startstopper
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type loggerKey struct{}
+
+// SetLogger attaches logger to the StartStopper, records carry name attribute.
+// Name is shared with SetMetrics. Nil logger disables logging.
+// Logger is available to workers via LoggerFromContext.
+// Takes effect on next Start.
+func (startStopper *StartStopper) SetLogger(name string, logger *slog.Logger) {
+	WithMutex(&startStopper.mu, func() {
+		startStopper.name = name
+		startStopper.logger = logger
+	})
+}
+
+// ContextWithLogger ...
+func ContextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// LoggerFromContext returns logger of the StartStopper run, slog.Default if there is none.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+	return slog.Default()
+}
+
+// runLogger of a single run, noop without logger
+type runLogger struct {
+	logger *slog.Logger
+}
+
+func newRunLogger(name string, logger *slog.Logger) runLogger {
+	if logger == nil {
+		return runLogger{}
+	}
+	return runLogger{logger: logger.With(slog.String("startstopper", name))}
+}
+
+func (l runLogger) log(level slog.Level, msg string, attrs ...slog.Attr) {
+	if l.logger != nil {
+		l.logger.LogAttrs(context.Background(), level, msg, attrs...)
+	}
+}
+
+func (l runLogger) starting() {
+	l.log(slog.LevelDebug, "starting")
+}
+
+func (l runLogger) started(latency time.Duration, err error) {
+	if err != nil {
+		l.log(slog.LevelError, "start failed", slog.Duration("latency", latency), slog.Any("error", err))
+		return
+	}
+	l.log(slog.LevelInfo, "started", slog.Duration("latency", latency))
+}
+
+func (l runLogger) stopping(cause error) {
+	l.log(slog.LevelInfo, "stopping", slog.Any("cause", cause))
+}
+
+func (l runLogger) killTimeout(timeout time.Duration) {
+	l.log(slog.LevelWarn, "kill timeout", slog.Duration("timeout", timeout))
+}
+
+func (l runLogger) stopped(duration time.Duration, cause error, err error) {
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelError
+	}
+	l.log(level, "stopped", slog.Duration("duration", duration), slog.Any("cause", cause), slog.Any("error", err))
+}
+
+func (l runLogger) restarting(attempt int, delay time.Duration, err error) {
+	l.log(slog.LevelWarn, "restarting", slog.Int("attempt", attempt), slog.Duration("delay", delay), slog.Any("error", err))
+}
@@ -0,0 +1,147 @@
+package startstopper_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logRecords collects JSON log records
+type logRecords struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (r *logRecords) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.buf.Write(p)
+}
+
+func (r *logRecords) records(t *testing.T) []map[string]any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var records []map[string]any
+	dec := json.NewDecoder(&r.buf)
+	for dec.More() {
+		var record map[string]any
+		require.NoError(t, dec.Decode(&record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestStartStopper_SetLogger(t *testing.T) {
+	t.Run("lifecycle", func(t *testing.T) {
+		var out logRecords
+		logger := slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+		startStopper := startstopper.New(t.Context(), func(context.Context) time.Duration { return time.Millisecond })
+		startStopper.SetLogger("db", logger)
+
+		// SUT
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil,
+			func(ctx context.Context, killCtx context.Context) error {
+				startstopper.LoggerFromContext(ctx).Info("worker")
+				<-killCtx.Done()
+				return errors.New("flush failed")
+			},
+		)
+		require.NoError(t, err)
+
+		startStopper.Close()
+		<-done
+
+		var msgs []string
+		for _, record := range out.records(t) {
+			assert.Equal(t, "db", record["startstopper"])
+			msgs = append(msgs, record["msg"].(string))
+
+			switch record["msg"] {
+			case "kill timeout":
+				assert.EqualValues(t, time.Millisecond, record["timeout"])
+			case "stopped":
+				assert.Equal(t, "ERROR", record["level"])
+				assert.Equal(t, "flush failed", record["error"])
+				assert.Contains(t, record, "duration")
+			}
+		}
+
+		assert.ElementsMatch(t, []string{"starting", "started", "worker", "stopping", "kill timeout", "stopped"}, msgs)
+	})
+
+	t.Run("no kill timeout after done", func(t *testing.T) {
+		tests := []struct {
+			name string
+			msgs []string
+			stop func(startStopper *startstopper.StartStopper)
+		}{
+			{name: "close", msgs: []string{"started", "stopping", "stopped"}, stop: (*startstopper.StartStopper).Close},
+			{name: "completed", msgs: []string{"started", "stopped"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var out logRecords
+				logger := slog.New(slog.NewJSONHandler(&out, nil))
+
+				startStopper := startstopper.New(t.Context(), func(context.Context) time.Duration { return 20 * time.Millisecond })
+				startStopper.SetLogger("db", logger)
+
+				worker := waitGraceful
+				if tt.stop == nil {
+					worker = func(context.Context, context.Context) error { return nil }
+				}
+
+				_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil, worker)
+				require.NoError(t, err)
+
+				// SUT
+				if tt.stop != nil {
+					tt.stop(startStopper)
+				}
+				<-done
+
+				// kill timeout has passed
+				time.Sleep(50 * time.Millisecond)
+
+				var msgs []string
+				for _, record := range out.records(t) {
+					msgs = append(msgs, record["msg"].(string))
+				}
+				assert.Equal(t, tt.msgs, msgs)
+			})
+		}
+	})
+
+	t.Run("start failed", func(t *testing.T) {
+		var out logRecords
+		logger := slog.New(slog.NewJSONHandler(&out, nil))
+
+		startStopper := startstopper.New(t.Context(), nil)
+		startStopper.SetLogger("db", logger)
+
+		// SUT
+		_, _, _, err := startStopper.StartWorkers(t.Context(), nil, func() error { return errors.New("no connection") })
+		require.Error(t, err)
+
+		records := out.records(t)
+		require.Len(t, records, 1)
+		assert.Equal(t, "start failed", records[0]["msg"])
+		assert.Equal(t, "no connection", records[0]["error"])
+	})
+
+	t.Run("default logger", func(t *testing.T) {
+		assert.Same(t, slog.Default(), startstopper.LoggerFromContext(t.Context()))
+	})
+}
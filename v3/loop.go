@@ -0,0 +1,216 @@
+package startstopper
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// DrainMode defines what Loop does with input on graceful shutdown.
+// Every mode is bounded by kill and input channel close.
+type DrainMode int
+
+const (
+	// DrainAll handles items buffered in input channel, returns once it is empty like Pool.
+	DrainAll DrainMode = iota
+	// DrainItems handles up to Items more items.
+	DrainItems
+	// DrainDeadline handles items for Timeout.
+	DrainDeadline
+	// DrainDrop returns immediately.
+	DrainDrop
+)
+
+// DrainPolicy ...
+// The zero value drains buffered items.
+type DrainPolicy struct {
+	Mode    DrainMode
+	Items   int           // DrainItems
+	Timeout time.Duration // DrainDeadline, since graceful shutdown begins
+}
+
+// LoopSpec ...
+// Ticks and Cases are served until graceful shutdown begins, then only input is drained.
+type LoopSpec struct {
+	Drain        DrainPolicy
+	TickInterval time.Duration                   // zero disables OnTick
+	OnTick       func(ctx context.Context) error // optional, ticks missed by slow handler are skipped
+	Clock        Clock                           // nil means SystemClock
+	Cases        []LoopCase                      // extra select cases, see OnReceive
+}
+
+// LoopCase is an extra select case of Loop.
+type LoopCase struct {
+	ch     reflect.Value
+	handle func(ctx context.Context, v reflect.Value) error
+}
+
+// OnReceive calls f with values received from ch by Loop.
+// The case is disabled once ch is closed.
+func OnReceive[E any](ch <-chan E, f func(ctx context.Context, v E) error) LoopCase {
+	return LoopCase{
+		ch: reflect.ValueOf(ch),
+		handle: func(ctx context.Context, v reflect.Value) error {
+			return f(ctx, recvValue[E](v))
+		},
+	}
+}
+
+// select case indexes of Loop, LoopSpec.Cases follow
+const (
+	loopKill = iota
+	loopGraceful
+	loopInput
+	loopTick
+	loopDeadline
+	loopCases
+)
+
+// Loop returns a Worker calling handler with items received from in.
+// Kill takes precedence: once kill context is done no more items are received,
+// an item received at the same moment is still handled, never dropped.
+// On graceful shutdown input is drained according to LoopSpec.Drain.
+// Handler, OnTick and Cases get kill context, their error stops the loop and is returned.
+// Returns nil on kill, when input channel is closed or drained.
+func Loop[T any](in <-chan T, handler Handler[T], spec LoopSpec) Worker {
+	return func(ctx context.Context, killCtx context.Context) error {
+		clock := spec.Clock
+		if clock == nil {
+			clock = SystemClock{}
+		}
+
+		cases := make([]reflect.SelectCase, loopCases+len(spec.Cases))
+		for i := range cases {
+			cases[i].Dir = reflect.SelectRecv
+		}
+		cases[loopKill].Chan = reflect.ValueOf(killCtx.Done())
+		cases[loopGraceful].Chan = reflect.ValueOf(ctx.Done())
+		cases[loopInput].Chan = reflect.ValueOf(in)
+		for i, c := range spec.Cases {
+			cases[loopCases+i].Chan = c.ch
+		}
+
+		var timers [loopCases]Timer
+		stop := func(i int) {
+			if timers[i] != nil {
+				timers[i].Stop()
+			}
+		}
+		defer stop(loopTick)
+		defer stop(loopDeadline)
+
+		// arm case to be received after d
+		arm := func(i int, d time.Duration) {
+			wake := make(chan struct{})
+			timers[i] = clock.AfterFunc(d, func() { close(wake) })
+			cases[i].Chan = reflect.ValueOf(wake)
+		}
+
+		ticking := spec.OnTick != nil && spec.TickInterval > 0
+		next := clock.Now()
+		armTick := func() {
+			now := clock.Now()
+			for !next.After(now) {
+				next = next.Add(spec.TickInterval)
+			}
+			arm(loopTick, next.Sub(now))
+		}
+		if ticking {
+			armTick()
+		}
+
+		draining := false
+		drained := 0
+		drainedAll := -1 // default case once input is empty, DrainAll
+
+		// beginDrain returns false if nothing is to be drained
+		beginDrain := func() bool {
+			draining = true
+			stop(loopTick)
+			for i := range cases {
+				if i != loopKill && i != loopInput {
+					cases[i].Chan = reflect.Value{}
+				}
+			}
+
+			switch spec.Drain.Mode {
+			case DrainDrop:
+				return false
+
+			case DrainItems:
+				return spec.Drain.Items > 0
+
+			case DrainDeadline:
+				arm(loopDeadline, spec.Drain.Timeout)
+
+			default:
+				drainedAll = len(cases)
+				cases = append(cases, reflect.SelectCase{Dir: reflect.SelectDefault})
+			}
+			return true
+		}
+
+		for {
+			// prioritize kill
+			if killCtx.Err() != nil {
+				return nil
+			}
+
+			chosen, recv, ok := reflect.Select(cases)
+
+			switch chosen {
+			case loopKill, loopDeadline, drainedAll:
+				return nil
+
+			case loopGraceful:
+				if !beginDrain() {
+					return nil
+				}
+
+			case loopInput:
+				if !ok {
+					return nil
+				}
+
+				err := handler(killCtx, recvValue[T](recv))
+				if err != nil {
+					return err
+				}
+
+				if draining && spec.Drain.Mode == DrainItems {
+					drained++
+					if drained >= spec.Drain.Items {
+						return nil
+					}
+				}
+
+			case loopTick:
+				err := spec.OnTick(killCtx)
+				if err != nil {
+					return err
+				}
+				armTick()
+
+			default:
+				if !ok {
+					cases[chosen].Chan = reflect.Value{}
+					continue
+				}
+
+				err := spec.Cases[chosen-loopCases].handle(killCtx, recv)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// recvValue converts received value, nil interface values included.
+func recvValue[E any](v reflect.Value) E {
+	var e E
+	if v.IsValid() {
+		reflect.ValueOf(&e).Elem().Set(v)
+	}
+	return e
+}
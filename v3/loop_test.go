@@ -0,0 +1,283 @@
+package startstopper_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/Darigaaz/startstopper/v3/startstoppertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runLoop runs worker in background, returns its result.
+func runLoop(ctx, killCtx context.Context, worker startstopper.Worker) <-chan error {
+	result := make(chan error, 1)
+	go func() {
+		result <- worker(ctx, killCtx)
+	}()
+	return result
+}
+
+// killedAtSelect is a kill context done before Loop selects, but after it checked Err.
+type killedAtSelect struct {
+	context.Context
+	checked atomic.Bool
+}
+
+func (ctx *killedAtSelect) Done() <-chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}
+
+func (ctx *killedAtSelect) Err() error {
+	if ctx.checked.Swap(true) {
+		return context.Canceled
+	}
+	return nil
+}
+
+func counting(handled *atomic.Int32) startstopper.Handler[int] {
+	return func(_ context.Context, _ int) error {
+		handled.Add(1)
+		return nil
+	}
+}
+
+func TestLoop(t *testing.T) {
+	t.Run("returns when input is closed", func(t *testing.T) {
+		in := make(chan int)
+		var handled atomic.Int32
+
+		// SUT
+		result := runLoop(t.Context(), t.Context(), startstopper.Loop(in, counting(&handled), startstopper.LoopSpec{}))
+
+		for i := 0; i < 3; i++ {
+			in <- i
+		}
+		close(in)
+
+		require.NoError(t, <-result)
+		assert.Equal(t, int32(3), handled.Load())
+	})
+
+	t.Run("handler error stops loop", func(t *testing.T) {
+		errHandler := errors.New("handler failed")
+		in := make(chan int)
+
+		// SUT
+		result := runLoop(t.Context(), t.Context(), startstopper.Loop(in, func(_ context.Context, _ int) error {
+			return errHandler
+		}, startstopper.LoopSpec{}))
+
+		in <- 1
+		require.ErrorIs(t, <-result, errHandler)
+	})
+
+	t.Run("kill takes precedence", func(t *testing.T) {
+		in := make(chan int, 3)
+		for i := 0; i < 3; i++ {
+			in <- i
+		}
+		var handled atomic.Int32
+
+		killCtx, kill := context.WithCancel(t.Context())
+		kill()
+
+		// SUT
+		result := runLoop(t.Context(), killCtx, startstopper.Loop(in, counting(&handled), startstopper.LoopSpec{}))
+
+		require.NoError(t, <-result)
+		assert.Zero(t, handled.Load())
+		assert.Len(t, in, 3)
+	})
+
+	t.Run("kill interrupts drain", func(t *testing.T) {
+		in := make(chan int)
+		var handled atomic.Int32
+
+		ctx, cancel := context.WithCancel(t.Context())
+		killCtx, kill := context.WithCancel(t.Context())
+
+		result := runLoop(ctx, killCtx, startstopper.Loop(in, counting(&handled), startstopper.LoopSpec{
+			Drain: startstopper.DrainPolicy{Mode: startstopper.DrainItems, Items: 5},
+		}))
+		in <- 1
+		cancel()
+
+		// SUT
+		kill()
+
+		require.NoError(t, <-result)
+		assert.Equal(t, int32(1), handled.Load())
+	})
+
+	t.Run("item received at kill is handled", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			in := make(chan int, 1)
+			in <- 1
+			var handled atomic.Int32
+
+			// SUT
+			result := runLoop(t.Context(), &killedAtSelect{Context: t.Context()}, startstopper.Loop(in, counting(&handled), startstopper.LoopSpec{}))
+
+			require.NoError(t, <-result)
+			// received item is handled
+			require.Equal(t, int32(1-len(in)), handled.Load())
+		}
+	})
+}
+
+func TestLoop_Drain(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  startstopper.DrainPolicy
+		handled int32
+	}{
+		{name: "items", policy: startstopper.DrainPolicy{Mode: startstopper.DrainItems, Items: 2}, handled: 2},
+		{name: "drop", policy: startstopper.DrainPolicy{Mode: startstopper.DrainDrop}, handled: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := make(chan int, 5)
+			var handled atomic.Int32
+
+			ctx, cancel := context.WithCancel(t.Context())
+			cancel()
+
+			// SUT
+			result := runLoop(ctx, t.Context(), startstopper.Loop(in, counting(&handled), startstopper.LoopSpec{
+				Drain: tt.policy,
+			}))
+
+			// let the loop begin drain with empty input
+			time.Sleep(10 * time.Millisecond)
+
+			for i := 0; i < 5; i++ {
+				in <- i
+			}
+			close(in)
+
+			require.NoError(t, <-result)
+			assert.Equal(t, tt.handled, handled.Load())
+		})
+	}
+
+	t.Run("all buffered", func(t *testing.T) {
+		in := make(chan int, 5)
+		for i := 0; i < 5; i++ {
+			in <- i
+		}
+		var handled atomic.Int32
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		// SUT
+		result := runLoop(ctx, t.Context(), startstopper.Loop(in, counting(&handled), startstopper.LoopSpec{}))
+
+		// input is never closed
+		require.NoError(t, <-result)
+		assert.Equal(t, int32(5), handled.Load())
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		clock := startstoppertest.NewFakeClock(time.Now())
+		in := make(chan int)
+		var handled atomic.Int32
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		// SUT
+		result := runLoop(ctx, t.Context(), startstopper.Loop(in, counting(&handled), startstopper.LoopSpec{
+			Drain: startstopper.DrainPolicy{Mode: startstopper.DrainDeadline, Timeout: time.Second},
+			Clock: clock,
+		}))
+
+		require.NoError(t, clock.BlockUntil(t.Context(), 1))
+		in <- 1
+		in <- 2
+		clock.Advance(time.Second)
+
+		require.NoError(t, <-result)
+		assert.Equal(t, int32(2), handled.Load())
+	})
+}
+
+func TestLoop_Cases(t *testing.T) {
+	t.Run("tick", func(t *testing.T) {
+		clock := startstoppertest.NewFakeClock(time.Now())
+		var ticks atomic.Int32
+
+		ctx, cancel := context.WithCancel(t.Context())
+
+		result := runLoop(ctx, t.Context(), startstopper.Loop(make(chan int), counting(new(atomic.Int32)), startstopper.LoopSpec{
+			Drain:        startstopper.DrainPolicy{Mode: startstopper.DrainDrop},
+			TickInterval: time.Second,
+			OnTick: func(_ context.Context) error {
+				if ticks.Add(1) == 4 {
+					// slow tick
+					clock.Advance(3500 * time.Millisecond)
+				}
+				return nil
+			},
+			Clock: clock,
+		}))
+
+		// SUT
+		for i := 0; i < 3; i++ {
+			require.NoError(t, clock.BlockUntil(t.Context(), 1))
+			clock.Advance(time.Second)
+		}
+		require.NoError(t, clock.BlockUntil(t.Context(), 1))
+		assert.Equal(t, int32(3), ticks.Load())
+
+		// missed ticks are skipped, next tick keeps the schedule
+		clock.Advance(time.Second)
+		require.NoError(t, clock.BlockUntil(t.Context(), 1))
+		assert.Equal(t, int32(4), ticks.Load())
+
+		clock.Advance(500 * time.Millisecond)
+		require.NoError(t, clock.BlockUntil(t.Context(), 1))
+		assert.Equal(t, int32(5), ticks.Load())
+
+		cancel()
+		require.NoError(t, <-result)
+		assert.Zero(t, clock.Pending())
+	})
+
+	t.Run("receive", func(t *testing.T) {
+		errExtra := errors.New("extra failed")
+		extra := make(chan string)
+		other := make(chan error)
+		var received []string
+
+		// SUT
+		result := runLoop(t.Context(), t.Context(), startstopper.Loop(make(chan int), counting(new(atomic.Int32)), startstopper.LoopSpec{
+			Cases: []startstopper.LoopCase{
+				startstopper.OnReceive(extra, func(_ context.Context, v string) error {
+					received = append(received, v)
+					return nil
+				}),
+				startstopper.OnReceive(other, func(_ context.Context, err error) error {
+					return err
+				}),
+			},
+		}))
+
+		extra <- "a"
+		extra <- "b"
+		close(extra)
+		other <- nil
+		other <- errExtra
+
+		require.ErrorIs(t, <-result, errExtra)
+		assert.Equal(t, []string{"a", "b"}, received)
+	})
+}
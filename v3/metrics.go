@@ -0,0 +1,113 @@
+package startstopper
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ShutdownOutcome tells how a run ended.
+type ShutdownOutcome int
+
+const (
+	ShutdownCompleted   ShutdownOutcome = iota // workers returned without shutdown request
+	ShutdownGraceful                           // workers returned before kill
+	ShutdownKillTimeout                        // kill timer fired
+	ShutdownKilled                             // Kill or KillWithCause
+)
+
+// String ...
+func (outcome ShutdownOutcome) String() string {
+	switch outcome {
+	case ShutdownCompleted:
+		return "completed"
+	case ShutdownGraceful:
+		return "graceful"
+	case ShutdownKillTimeout:
+		return "kill_timeout"
+	case ShutdownKilled:
+		return "killed"
+	default:
+		return "unknown"
+	}
+}
+
+// Metrics is called by StartStopper at lifecycle points.
+// Must be threadsafe and must not block.
+type Metrics interface {
+	// ObserveStart after startFn, latency includes startFn. Nil err means the run is running.
+	ObserveStart(name string, latency time.Duration, err error)
+	// ObserveShutdown when the run is done, duration is measured from graceful shutdown begin.
+	ObserveShutdown(name string, duration time.Duration, outcome ShutdownOutcome)
+	// ObserveRestart before Run restarts an attempt.
+	ObserveRestart(name string, attempt int)
+}
+
+// SetMetrics reports lifecycle of the StartStopper to metrics with name label.
+// Nil metrics disables reporting.
+// Takes effect on next Start.
+func (startStopper *StartStopper) SetMetrics(name string, metrics Metrics) {
+	WithMutex(&startStopper.mu, func() {
+		startStopper.name = name
+		startStopper.metrics = metrics
+	})
+}
+
+// runMetrics of a single run, noop without Metrics
+type runMetrics struct {
+	name    string
+	metrics Metrics
+	clock   Clock
+
+	mu         sync.Mutex
+	stoppingAt time.Time
+}
+
+func (m *runMetrics) start(latency time.Duration, err error) {
+	if m.metrics != nil {
+		m.metrics.ObserveStart(m.name, latency, err)
+	}
+}
+
+// stopping marks graceful shutdown begin, first call wins.
+func (m *runMetrics) stopping() {
+	WithMutex(&m.mu, func() {
+		if m.stoppingAt.IsZero() {
+			m.stoppingAt = m.clock.Now()
+		}
+	})
+}
+
+func (m *runMetrics) shutdown(gracefulCtx, killCtx context.Context) {
+	if m.metrics == nil {
+		return
+	}
+
+	m.stopping()
+	duration := WithMutex1(&m.mu, func() time.Duration {
+		return m.clock.Now().Sub(m.stoppingAt)
+	})
+
+	m.metrics.ObserveShutdown(m.name, duration, shutdownOutcome(gracefulCtx, killCtx))
+}
+
+func (m *runMetrics) restart(attempt int) {
+	if m.metrics != nil {
+		m.metrics.ObserveRestart(m.name, attempt)
+	}
+}
+
+func shutdownOutcome(gracefulCtx, killCtx context.Context) ShutdownOutcome {
+	if cause := context.Cause(killCtx); cause != nil {
+		if errors.Is(cause, ErrKillTimeout) {
+			return ShutdownKillTimeout
+		}
+		return ShutdownKilled
+	}
+
+	if errors.Is(context.Cause(gracefulCtx), ErrCompleted) {
+		return ShutdownCompleted
+	}
+	return ShutdownGraceful
+}
@@ -0,0 +1,96 @@
+package startstopper_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartStopper_SetMetrics(t *testing.T) {
+	metrics := startstopper.NewMemoryMetrics(0.5, 1)
+
+	// graceful
+	svc := startstopper.New(t.Context(), nil)
+	svc.SetMetrics("svc", metrics)
+
+	_, _, _, err := svc.StartWorkers(t.Context(), nil, nil, waitGraceful)
+	require.NoError(t, err)
+	svc.Close()
+
+	// kill timeout
+	stuck := startstopper.New(t.Context(), func(context.Context) time.Duration { return time.Millisecond })
+	stuck.SetMetrics("stuck", metrics)
+
+	_, _, _, err = stuck.StartWorkers(t.Context(), nil, nil, func(_ context.Context, killCtx context.Context) error {
+		<-killCtx.Done()
+		return nil
+	})
+	require.NoError(t, err)
+	stuck.Close()
+
+	// start error
+	_, _, _, err = stuck.StartWorkers(t.Context(), nil, func() error { return errors.New("failed") })
+	require.Error(t, err)
+
+	// restarts, still running
+	runner := startstopper.New(t.Context(), nil)
+	runner.SetMetrics("runner", metrics)
+	runner.SetRestartPolicy(startstopper.RestartPolicy{
+		Mode:           startstopper.RestartAlways,
+		InitialBackoff: time.Microsecond,
+	})
+
+	attempts := 0
+	readyCh := make(chan error, 1)
+	go func() {
+		_ = runner.Run(t.Context(), readyCh, nil, func(ctx context.Context, _ context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return nil
+			}
+			<-ctx.Done()
+			return nil
+		})
+	}()
+	require.NoError(t, <-readyCh)
+	require.NoError(t, runner.WaitFor(t.Context(), startstopper.StateRunning))
+	require.Eventually(t, func() bool {
+		var b strings.Builder
+		_ = metrics.WritePrometheus(&b)
+		return strings.Contains(b.String(), `startstopper_restarts_total{name="runner"} 2`)
+	}, time.Second, time.Millisecond)
+
+	// SUT
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	assert.Equal(t, "application/openmetrics-text; version=1.0.0; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	for _, line := range []string{
+		`# TYPE startstopper_start_duration_seconds histogram`,
+		`startstopper_start_duration_seconds_bucket{name="svc",result="ok",le="0.5"} 1`,
+		`startstopper_start_duration_seconds_count{name="stuck",result="error"} 1`,
+		`startstopper_shutdown_duration_seconds_count{name="svc",outcome="graceful"} 1`,
+		`startstopper_shutdown_duration_seconds_bucket{name="stuck",outcome="kill_timeout",le="+Inf"} 1`,
+		`# TYPE startstopper_restarts counter`,
+		`startstopper_restarts_total{name="runner"} 2`,
+		`startstopper_running{name="runner"} 1`,
+		`startstopper_running{name="svc"} 0`,
+	} {
+		assert.Contains(t, body, line+"\n")
+	}
+	assert.True(t, strings.HasSuffix(body, "# EOF\n"))
+
+	runner.Close()
+}
@@ -0,0 +1,226 @@
+package startstopper
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// MetricsBucketsDefault in seconds.
+	MetricsBucketsDefault = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+)
+
+const (
+	openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	prometheusContentType  = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// MemoryMetrics keeps counters and histograms in memory.
+// Renders them in OpenMetrics or Prometheus text format, see ServeHTTP.
+//
+//	startstopper_start_duration_seconds{name, result}       histogram
+//	startstopper_shutdown_duration_seconds{name, outcome}   histogram
+//	startstopper_restarts_total{name}                       counter
+//	startstopper_running{name}                              gauge
+type MemoryMetrics struct {
+	buckets []float64
+
+	mu        sync.Mutex
+	starts    map[[2]string]*histogram // name, result
+	shutdowns map[[2]string]*histogram // name, outcome
+	restarts  map[string]uint64
+	running   map[string]int64
+}
+
+// NewMemoryMetrics with histogram buckets in seconds, MetricsBucketsDefault if none.
+func NewMemoryMetrics(buckets ...float64) *MemoryMetrics {
+	if len(buckets) == 0 {
+		buckets = MetricsBucketsDefault
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &MemoryMetrics{
+		buckets:   buckets,
+		starts:    map[[2]string]*histogram{},
+		shutdowns: map[[2]string]*histogram{},
+		restarts:  map[string]uint64{},
+		running:   map[string]int64{},
+	}
+}
+
+// ObserveStart ...
+func (m *MemoryMetrics) ObserveStart(name string, latency time.Duration, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+
+	WithMutex(&m.mu, func() {
+		m.histogram(m.starts, [2]string{name, result}).observe(latency.Seconds())
+		if err == nil {
+			m.running[name]++
+		}
+	})
+}
+
+// ObserveShutdown ...
+func (m *MemoryMetrics) ObserveShutdown(name string, duration time.Duration, outcome ShutdownOutcome) {
+	WithMutex(&m.mu, func() {
+		m.histogram(m.shutdowns, [2]string{name, outcome.String()}).observe(duration.Seconds())
+		m.running[name]--
+	})
+}
+
+// ObserveRestart ...
+func (m *MemoryMetrics) ObserveRestart(name string, _ int) {
+	WithMutex(&m.mu, func() {
+		m.restarts[name]++
+	})
+}
+
+// ServeHTTP renders OpenMetrics if requested by Accept header, Prometheus text format otherwise.
+func (m *MemoryMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	openMetrics := strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text")
+
+	contentType := prometheusContentType
+	if openMetrics {
+		contentType = openMetricsContentType
+	}
+	w.Header().Set("Content-Type", contentType)
+
+	_ = m.write(w, openMetrics)
+}
+
+// WriteOpenMetrics renders metrics in OpenMetrics text format.
+func (m *MemoryMetrics) WriteOpenMetrics(w io.Writer) error {
+	return m.write(w, true)
+}
+
+// WritePrometheus renders metrics in Prometheus text format.
+func (m *MemoryMetrics) WritePrometheus(w io.Writer) error {
+	return m.write(w, false)
+}
+
+func (m *MemoryMetrics) write(w io.Writer, openMetrics bool) error {
+	b := bufio.NewWriter(w)
+
+	WithMutex(&m.mu, func() {
+		writeHistograms(b, "startstopper_start_duration_seconds", "Start latency including startFn.",
+			[2]string{"name", "result"}, m.starts)
+
+		writeHistograms(b, "startstopper_shutdown_duration_seconds", "Time from graceful shutdown begin to done.",
+			[2]string{"name", "outcome"}, m.shutdowns)
+
+		restarts := "startstopper_restarts_total"
+		if openMetrics {
+			restarts = "startstopper_restarts"
+		}
+		fmt.Fprintf(b, "# HELP %s Restarts by Run.\n", restarts)
+		fmt.Fprintf(b, "# TYPE %s counter\n", restarts)
+		for _, name := range sortedKeys(m.restarts) {
+			fmt.Fprintf(b, "startstopper_restarts_total{name=\"%s\"} %d\n", escapeLabel(name), m.restarts[name])
+		}
+
+		fmt.Fprintf(b, "# HELP startstopper_running Running instances.\n")
+		fmt.Fprintf(b, "# TYPE startstopper_running gauge\n")
+		for _, name := range sortedKeys(m.running) {
+			fmt.Fprintf(b, "startstopper_running{name=\"%s\"} %d\n", escapeLabel(name), m.running[name])
+		}
+	})
+
+	if openMetrics {
+		fmt.Fprintf(b, "# EOF\n")
+	}
+
+	return b.Flush()
+}
+
+func (m *MemoryMetrics) histogram(series map[[2]string]*histogram, labels [2]string) *histogram {
+	h, ok := series[labels]
+	if !ok {
+		h = &histogram{
+			buckets: m.buckets,
+			counts:  make([]uint64, len(m.buckets)),
+		}
+		series[labels] = h
+	}
+	return h
+}
+
+type histogram struct {
+	buckets []float64
+	counts  []uint64 // not cumulative
+	count   uint64
+	sum     float64
+}
+
+func (h *histogram) observe(v float64) {
+	h.count++
+	h.sum += v
+
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		h.counts[i]++
+	}
+}
+
+func writeHistograms(w io.Writer, metric string, help string, labelNames [2]string, series map[[2]string]*histogram) {
+	fmt.Fprintf(w, "# HELP %s %s\n", metric, help)
+	fmt.Fprintf(w, "# TYPE %s histogram\n", metric)
+
+	keys := make([][2]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+
+	for _, key := range keys {
+		h := series[key]
+		labels := fmt.Sprintf("%s=\"%s\",%s=\"%s\"",
+			labelNames[0], escapeLabel(key[0]), labelNames[1], escapeLabel(key[1]))
+
+		var cumulative uint64
+		for i, le := range h.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", metric, labels, formatFloat(le), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", metric, labels, h.count)
+		fmt.Fprintf(w, "%s_sum{%s} %s\n", metric, labels, formatFloat(h.sum))
+		fmt.Fprintf(w, "%s_count{%s} %d\n", metric, labels, h.count)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
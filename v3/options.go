@@ -0,0 +1,213 @@
+package startstopper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+var (
+	ErrInvalidOption = errors.New("invalid option")
+	errInvalidOption = NewErrorCode(ErrInvalidOption, "STARTSTOPPER_ERR_INVALID_OPTION")
+)
+
+// Config of StartStopper, zero values mean defaults.
+// See NewWithOptions and Option.
+type Config struct {
+	KillTimeout         time.Duration                           // zero means KillTimeoutDefault
+	KillTimeoutProvider func(ctx context.Context) time.Duration // overrides KillTimeout
+	Name                string
+	Logger              *slog.Logger // nil disables logging
+	Clock               Clock        // nil means SystemClock
+	Metrics             Metrics      // nil disables metrics
+	Tracer              Tracer       // nil means NoopTracer
+	RestartPolicy       RestartPolicy
+	FailFast            bool
+	Repanic             bool
+	ShutdownPhases      []ShutdownPhase
+	AbandonTimeout      time.Duration
+	StuckTimeout        time.Duration
+	StuckSink           StuckSink
+}
+
+// Option modifies Config.
+type Option func(config *Config)
+
+// NewWithOptions like New, invalid options are reported by Start.
+func NewWithOptions(opts ...Option) *StartStopper {
+	var inst StartStopper
+	_ = inst.InitWithOptions(opts...)
+	return &inst
+}
+
+// InitWithOptions like Init, returns ErrInvalidOption if options are invalid.
+func (startStopper *StartStopper) InitWithOptions(opts ...Option) error {
+	var config Config
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	startStopper.initOnce.Do(func() {
+		startStopper.init(config)
+	})
+	return startStopper.initErr
+}
+
+// WithConfig replaces the whole config, options after it modify it.
+func WithConfig(c Config) Option {
+	return func(config *Config) {
+		*config = c
+	}
+}
+
+// WithKillTimeout between graceful shutdown begin and kill.
+func WithKillTimeout(timeout time.Duration) Option {
+	return func(config *Config) {
+		config.KillTimeout = timeout
+	}
+}
+
+// WithKillTimeoutProvider is called with kill context when graceful shutdown begins.
+func WithKillTimeoutProvider(killTimeoutProvider func(ctx context.Context) time.Duration) Option {
+	return func(config *Config) {
+		config.KillTimeoutProvider = killTimeoutProvider
+	}
+}
+
+// WithName for logger and metrics.
+func WithName(name string) Option {
+	return func(config *Config) {
+		config.Name = name
+	}
+}
+
+// WithLogger see SetLogger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(config *Config) {
+		config.Logger = logger
+	}
+}
+
+// WithClock see SetClock.
+func WithClock(clock Clock) Option {
+	return func(config *Config) {
+		config.Clock = clock
+	}
+}
+
+// WithMetrics see SetMetrics.
+func WithMetrics(metrics Metrics) Option {
+	return func(config *Config) {
+		config.Metrics = metrics
+	}
+}
+
+// WithTracer see SetTracer.
+func WithTracer(tracer Tracer) Option {
+	return func(config *Config) {
+		config.Tracer = tracer
+	}
+}
+
+// WithRestartPolicy see SetRestartPolicy.
+func WithRestartPolicy(policy RestartPolicy) Option {
+	return func(config *Config) {
+		config.RestartPolicy = policy
+	}
+}
+
+// WithFailFast see SetFailFast.
+func WithFailFast(failFast bool) Option {
+	return func(config *Config) {
+		config.FailFast = failFast
+	}
+}
+
+// WithRepanic see SetRepanic.
+func WithRepanic(repanic bool) Option {
+	return func(config *Config) {
+		config.Repanic = repanic
+	}
+}
+
+// WithShutdownPhases see SetShutdownPhases. Names must be unique.
+func WithShutdownPhases(phases ...ShutdownPhase) Option {
+	return func(config *Config) {
+		config.ShutdownPhases = phases
+	}
+}
+
+// WithAbandonTimeout see SetAbandonTimeout.
+func WithAbandonTimeout(timeout time.Duration) Option {
+	return func(config *Config) {
+		config.AbandonTimeout = timeout
+	}
+}
+
+// WithStuckWatchdog see SetStuckWatchdog.
+func WithStuckWatchdog(timeout time.Duration, sink StuckSink) Option {
+	return func(config *Config) {
+		config.StuckTimeout = timeout
+		config.StuckSink = sink
+	}
+}
+
+// validate returns joined ErrInvalidOption errors.
+func (config Config) validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if config.KillTimeout < 0 {
+		invalid("kill timeout %s is negative", config.KillTimeout)
+	}
+
+	if err := config.RestartPolicy.validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	names := make(map[string]bool, len(config.ShutdownPhases))
+	for _, phase := range config.ShutdownPhases {
+		if phase.Name == "" {
+			invalid("shutdown phase name is empty")
+		} else if names[phase.Name] {
+			invalid("shutdown phase %q is duplicated", phase.Name)
+		}
+		names[phase.Name] = true
+
+		if phase.Timeout < 0 {
+			invalid("shutdown phase %q timeout %s is negative", phase.Name, phase.Timeout)
+		}
+	}
+
+	if config.AbandonTimeout < 0 {
+		invalid("abandon timeout %s is negative", config.AbandonTimeout)
+	}
+	if config.StuckTimeout < 0 {
+		invalid("stuck timeout %s is negative", config.StuckTimeout)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return JoinErrors(append([]error{errInvalidOption}, errs...)...)
+}
+
+func (policy RestartPolicy) validate() error {
+	switch {
+	case policy.Mode < RestartNever || policy.Mode > RestartAlways:
+		return fmt.Errorf("restart mode %d is unknown", policy.Mode)
+	case policy.MaxAttempts < 0:
+		return fmt.Errorf("restart max attempts %d is negative", policy.MaxAttempts)
+	case policy.InitialBackoff < 0 || policy.MaxBackoff < 0:
+		return fmt.Errorf("restart backoff is negative")
+	case policy.Multiplier != 0 && policy.Multiplier < 1:
+		return fmt.Errorf("restart multiplier %g is less than 1", policy.Multiplier)
+	case policy.Jitter < 0 || policy.Jitter > 1:
+		return fmt.Errorf("restart jitter %g is out of [0, 1]", policy.Jitter)
+	}
+	return nil
+}
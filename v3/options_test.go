@@ -0,0 +1,98 @@
+package startstopper_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	t.Run("options are applied", func(t *testing.T) {
+		var out logRecords
+		recorder := startstopper.NewSpanRecorder()
+
+		startStopper := startstopper.NewWithOptions(
+			startstopper.WithName("db"),
+			startstopper.WithLogger(slog.New(slog.NewJSONHandler(&out, nil))),
+			startstopper.WithTracer(recorder),
+			startstopper.WithKillTimeout(time.Millisecond),
+			startstopper.WithFailFast(true),
+		)
+
+		errWorker := errors.New("worker failed")
+
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil,
+			func(_ context.Context, killCtx context.Context) error {
+				<-killCtx.Done()
+				return nil
+			},
+			func(context.Context, context.Context) error { return errWorker },
+		)
+		require.NoError(t, err)
+		<-done
+
+		// fail fast, then kill timeout
+		require.ErrorIs(t, startStopper.Cause(), errWorker)
+		require.ErrorIs(t, startStopper.Cause(), startstopper.ErrKillTimeout)
+
+		records := out.records(t)
+		require.NotEmpty(t, records)
+		assert.Equal(t, "db", records[0]["startstopper"])
+		assert.Equal(t, "db", recorder.Spans()[0].Attrs["startstopper.name"].String())
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		var startStopper startstopper.StartStopper
+
+		// SUT
+		err := startStopper.InitWithOptions(
+			startstopper.WithKillTimeout(-time.Second),
+			startstopper.WithRestartPolicy(startstopper.RestartPolicy{Jitter: 2}),
+			startstopper.WithShutdownPhases(
+				startstopper.ShutdownPhase{Name: "drain"},
+				startstopper.ShutdownPhase{Name: "drain"},
+			),
+		)
+
+		require.ErrorIs(t, err, startstopper.ErrInvalidOption)
+		require.True(t, startstopper.MatchErrorCodes(err, "STARTSTOPPER_ERR_INVALID_OPTION"))
+		assert.Contains(t, err.Error(), "kill timeout -1s is negative")
+		assert.Contains(t, err.Error(), "restart jitter 2 is out of [0, 1]")
+		assert.Contains(t, err.Error(), `shutdown phase "drain" is duplicated`)
+
+		readyCh := make(chan error, 1)
+		_, _, _, err = startStopper.StartWorkers(t.Context(), readyCh, nil)
+		require.ErrorIs(t, err, startstopper.ErrInvalidOption)
+		require.ErrorIs(t, <-readyCh, startstopper.ErrInvalidOption)
+	})
+
+	t.Run("config", func(t *testing.T) {
+		startStopper := startstopper.NewWithOptions(
+			startstopper.WithConfig(startstopper.Config{
+				Name:        "db",
+				KillTimeout: time.Hour,
+			}),
+			startstopper.WithKillTimeoutProvider(func(context.Context) time.Duration { return time.Millisecond }),
+		)
+
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil,
+			func(_ context.Context, killCtx context.Context) error {
+				<-killCtx.Done()
+				return nil
+			},
+		)
+		require.NoError(t, err)
+
+		// SUT
+		startStopper.Close()
+		<-done
+
+		require.ErrorIs(t, startStopper.Cause(), startstopper.ErrKillTimeout)
+	})
+}
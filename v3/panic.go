@@ -0,0 +1,62 @@
+package startstopper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+var (
+	ErrPanic = errors.New("panic")
+)
+
+// PanicErrorCode is the code of recovered worker panics.
+const PanicErrorCode = "STARTSTOPPER_ERR_PANIC"
+
+// PanicError is a recovered worker panic.
+// Use errors.As to get it from Err.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+// Error ...
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns ErrPanic and panic value if it is an error.
+func (e *PanicError) Unwrap() []error {
+	if err, ok := e.Value.(error); ok {
+		return []error{ErrPanic, err}
+	}
+	return []error{ErrPanic}
+}
+
+// SetRepanic makes the run panic again once shutdown after recovered worker panic completes.
+// For crash-only services. Takes effect on next Start.
+func (startStopper *StartStopper) SetRepanic(repanic bool) {
+	WithMutex(&startStopper.mu, func() {
+		startStopper.repanic = repanic
+	})
+}
+
+// callWorker recovers worker panic into error coded with PanicErrorCode.
+func callWorker(worker Worker, ctx context.Context, killCtx context.Context) (err error, panicErr *PanicError) {
+	defer func() {
+		if value := recover(); value != nil {
+			panicErr = &PanicError{
+				Value: value,
+				Stack: debug.Stack(),
+			}
+			err = NewErrorCode(panicErr, PanicErrorCode)
+		}
+	}()
+
+	return worker(ctx, killCtx), nil
+}
+
+func repanic(panicErr *PanicError) {
+	panic(fmt.Sprintf("%v [recovered by startstopper]\n\n%s", panicErr.Value, panicErr.Stack))
+}
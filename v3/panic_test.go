@@ -0,0 +1,113 @@
+package startstopper_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartStopper_Panic(t *testing.T) {
+	t.Run("worker panic closes the run", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		var graceful bool
+
+		// SUT
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil,
+			func(ctx context.Context, _ context.Context) error {
+				<-ctx.Done()
+				graceful = true
+				return nil
+			},
+			func(context.Context, context.Context) error {
+				panic("boom")
+			},
+		)
+		require.NoError(t, err)
+
+		<-done
+
+		assert.True(t, graceful)
+
+		err = startStopper.Err()
+		require.ErrorIs(t, err, startstopper.ErrPanic)
+		require.True(t, startstopper.MatchErrorCodes(err, startstopper.PanicErrorCode))
+		require.ErrorIs(t, startStopper.Cause(), startstopper.ErrPanic)
+
+		var panicErr *startstopper.PanicError
+		require.ErrorAs(t, err, &panicErr)
+		assert.Equal(t, "boom", panicErr.Value)
+		assert.Contains(t, string(panicErr.Stack), "panic_test.go")
+	})
+
+	t.Run("panic value error is unwrapped", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		errBoom := errors.New("boom")
+
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil, waitGraceful)
+		require.NoError(t, err)
+
+		// SUT
+		require.NoError(t, startStopper.Go(func(context.Context, context.Context) error {
+			panic(errBoom)
+		}))
+
+		<-done
+
+		require.ErrorIs(t, startStopper.Err(), startstopper.ErrPanic)
+		require.ErrorIs(t, startStopper.Err(), errBoom)
+	})
+
+	t.Run("Run restarts after panic", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+		startStopper.SetRestartPolicy(startstopper.RestartPolicy{
+			Mode:           startstopper.RestartOnFailure,
+			MaxAttempts:    2,
+			InitialBackoff: 1,
+		})
+
+		attempts := 0
+
+		// SUT
+		err := startStopper.Run(t.Context(), nil, nil, func(context.Context, context.Context) error {
+			attempts++
+			panic("boom")
+		})
+
+		require.ErrorIs(t, err, startstopper.ErrPanic)
+		assert.Equal(t, 2, attempts)
+	})
+}
+
+func TestStartStopper_SetRepanic(t *testing.T) {
+	if os.Getenv("STARTSTOPPER_TEST_REPANIC") == "1" {
+		startStopper := startstopper.New(context.Background(), nil)
+		startStopper.SetRepanic(true)
+
+		_, _, done, _ := startStopper.StartWorkers(context.Background(), nil, nil,
+			func(context.Context, context.Context) error {
+				panic("boom")
+			},
+		)
+		<-done
+
+		select {} // repanic crashes the process
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestStartStopper_SetRepanic$")
+	cmd.Env = append(os.Environ(), "STARTSTOPPER_TEST_REPANIC=1")
+
+	// SUT
+	out, err := cmd.CombinedOutput()
+
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Contains(t, string(out), "panic: boom [recovered by startstopper]")
+}
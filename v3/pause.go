@@ -0,0 +1,119 @@
+package startstopper
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrNotRunning = errors.New("not running")
+	errNotRunning = NewErrorCode(ErrNotRunning, "STARTSTOPPER_ERR_NOT_RUNNING")
+)
+
+// pause state of a single run, guarded by mu
+type pauseState struct {
+	paused  chan struct{} // closed while paused
+	resumed chan struct{} // closed while not paused
+	stop    func() bool   // stops auto resume of current pause
+	epoch   int           // increments on each Pause
+}
+
+func newPauseState() *pauseState {
+	return &pauseState{
+		paused:  make(chan struct{}),
+		resumed: makeClosedChan[struct{}](),
+	}
+}
+
+// Pause the running service until Resume is called or ctx is done.
+// Workers select on Paused and stop pulling work, see WaitResumed.
+// Close and Kill work while paused.
+// Returns ErrNotRunning unless running or paused.
+func (startStopper *StartStopper) Pause(ctx context.Context) error {
+	return WithMutex1(&startStopper.mu, func() error {
+		pause := startStopper.pause
+		if pause == nil {
+			return errNotRunning
+		}
+
+		gen := startStopper.generation()
+		if !startStopper.setState(gen, StatePaused, StateRunning) {
+			if startStopper.State() == StatePaused {
+				return nil
+			}
+			return errNotRunning
+		}
+
+		close(pause.paused)
+		pause.resumed = make(chan struct{})
+		pause.epoch++
+
+		epoch := pause.epoch
+		pause.stop = context.AfterFunc(ctx, func() {
+			WithMutex(&startStopper.mu, func() {
+				if startStopper.pause == pause && pause.epoch == epoch {
+					startStopper.resume(pause, gen)
+				}
+			})
+		})
+
+		return nil
+	})
+}
+
+// Resume paused service. No-op if not paused.
+func (startStopper *StartStopper) Resume() {
+	WithMutex(&startStopper.mu, func() {
+		if startStopper.pause != nil {
+			startStopper.resume(startStopper.pause, startStopper.generation())
+		}
+	})
+}
+
+// resume, mu must be held.
+func (startStopper *StartStopper) resume(pause *pauseState, gen uint64) {
+	select {
+	case <-pause.paused:
+	default:
+		// not paused
+		return
+	}
+
+	pause.stop()
+	pause.paused = make(chan struct{})
+	close(pause.resumed)
+
+	startStopper.setState(gen, StateRunning, StatePaused)
+}
+
+// Paused returns a channel that's closed while paused.
+// After Resume it returns a new channel.
+func (startStopper *StartStopper) Paused() <-chan struct{} {
+	return WithMutex1(&startStopper.mu, func() <-chan struct{} {
+		if startStopper.pause == nil {
+			return make(chan struct{})
+		}
+		return startStopper.pause.paused
+	})
+}
+
+// Resumed returns a channel that's closed while not paused.
+func (startStopper *StartStopper) Resumed() <-chan struct{} {
+	return WithMutex1(&startStopper.mu, func() <-chan struct{} {
+		if startStopper.pause == nil {
+			return alwaysClosedChan
+		}
+		return startStopper.pause.resumed
+	})
+}
+
+// WaitResumed blocks while paused, returns ctx error if ctx is done first.
+// Pass graceful context, so paused workers stop on Close.
+func (startStopper *StartStopper) WaitResumed(ctx context.Context) error {
+	select {
+	case <-startStopper.Resumed():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,195 @@
+package startstopper_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestStartStopper_Pause(t *testing.T) {
+	t.Run("pause and resume", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		transitions, unsubscribe := startStopper.Subscribe()
+		t.Cleanup(unsubscribe)
+
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil, waitGraceful)
+		require.NoError(t, err)
+		assert.True(t, isClosed(startStopper.Resumed()))
+		assert.False(t, isClosed(startStopper.Paused()))
+
+		// SUT
+		require.NoError(t, startStopper.Pause(t.Context()))
+		require.NoError(t, startStopper.Pause(t.Context()))
+
+		assert.Equal(t, startstopper.StatePaused, startStopper.State())
+		assert.True(t, isClosed(startStopper.Paused()))
+		assert.False(t, isClosed(startStopper.Resumed()))
+
+		resumed := make(chan error, 1)
+		go func() {
+			resumed <- startStopper.WaitResumed(t.Context())
+		}()
+
+		startStopper.Resume()
+		startStopper.Resume()
+		require.NoError(t, <-resumed)
+
+		assert.Equal(t, startstopper.StateRunning, startStopper.State())
+		assert.True(t, isClosed(startStopper.Resumed()))
+		assert.False(t, isClosed(startStopper.Paused()))
+
+		startStopper.Close()
+		<-done
+
+		assert.Equal(t, []startstopper.State{
+			startstopper.StateIdle,
+			startstopper.StateStarting,
+			startstopper.StateRunning,
+			startstopper.StatePaused,
+			startstopper.StateRunning,
+			startstopper.StateStopping,
+			startstopper.StateStopped,
+		}, collectStates(transitions, 6))
+	})
+
+	t.Run("resumes when ctx is done", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil, waitGraceful)
+		require.NoError(t, err)
+
+		pauseCtx, cancel := context.WithCancel(t.Context())
+
+		// SUT
+		require.NoError(t, startStopper.Pause(pauseCtx))
+		resumed := startStopper.Resumed()
+		cancel()
+
+		<-resumed
+		require.NoError(t, startStopper.WaitFor(t.Context(), startstopper.StateRunning))
+
+		startStopper.Close()
+		<-done
+	})
+
+	t.Run("stale ctx does not resume next pause", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil, waitGraceful)
+		require.NoError(t, err)
+
+		pauseCtx, cancel := context.WithCancel(t.Context())
+		require.NoError(t, startStopper.Pause(pauseCtx))
+		startStopper.Resume()
+
+		// SUT
+		require.NoError(t, startStopper.Pause(t.Context()))
+		cancel()
+
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, startstopper.StatePaused, startStopper.State())
+
+		startStopper.Close()
+		<-done
+	})
+
+	t.Run("close while paused", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		worker := func(ctx context.Context, _ context.Context) error {
+			<-startStopper.Paused()
+			return startStopper.WaitResumed(ctx)
+		}
+
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil, worker)
+		require.NoError(t, err)
+		require.NoError(t, startStopper.Pause(t.Context()))
+
+		// SUT
+		startStopper.Close()
+
+		<-done
+		assert.ErrorIs(t, startStopper.Cause(), startstopper.ErrClosed)
+		assert.ErrorIs(t, startStopper.Err(), context.Canceled)
+		assert.Equal(t, startstopper.StateStopped, startStopper.State())
+	})
+
+	t.Run("kill while paused", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		worker := func(_ context.Context, killCtx context.Context) error {
+			// ignore graceful shutdown
+			return startStopper.WaitResumed(killCtx)
+		}
+
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil, worker)
+		require.NoError(t, err)
+		require.NoError(t, startStopper.Pause(t.Context()))
+
+		// SUT
+		startStopper.Kill()
+
+		<-done
+		assert.ErrorIs(t, startStopper.Cause(), startstopper.ErrKilled)
+		assert.Equal(t, startstopper.StateStopped, startStopper.State())
+	})
+
+	t.Run("not running", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		// SUT
+		require.ErrorIs(t, startStopper.Pause(t.Context()), startstopper.ErrNotRunning)
+		startStopper.Resume()
+		assert.True(t, isClosed(startStopper.Resumed()))
+
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil, waitGraceful)
+		require.NoError(t, err)
+		startStopper.Close()
+		<-done
+
+		require.ErrorIs(t, startStopper.Pause(t.Context()), startstopper.ErrNotRunning)
+		assert.Equal(t, startstopper.StateStopped, startStopper.State())
+	})
+}
+
+func TestProbes_Paused(t *testing.T) {
+	startStopper := startstopper.New(t.Context(), nil)
+
+	probes := startstopper.NewProbes(0)
+	require.NoError(t, probes.AddService("srv", startStopper))
+	handler := probes.Handler()
+
+	_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil, waitGraceful)
+	require.NoError(t, err)
+
+	// SUT
+	require.NoError(t, startStopper.Pause(t.Context()))
+
+	code, _ := probe(t, handler, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	code, _ = probe(t, handler, "/livez")
+	assert.Equal(t, http.StatusOK, code)
+
+	startStopper.Resume()
+
+	code, _ = probe(t, handler, "/readyz")
+	assert.Equal(t, http.StatusOK, code)
+
+	startStopper.Close()
+	<-done
+}
@@ -0,0 +1,230 @@
+package startstopper
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// OverlapPolicy defines what Periodic does on tick while the previous job is running.
+type OverlapPolicy int
+
+const (
+	// OverlapSkip drops the tick.
+	OverlapSkip OverlapPolicy = iota
+	// OverlapQueue runs the job once more after the previous one returns, further ticks are dropped.
+	OverlapQueue
+	// OverlapAllow runs jobs concurrently.
+	OverlapAllow
+)
+
+// MissedPolicy defines what Periodic does with ticks missed because the timer fired late,
+// e.g. after the process was suspended.
+type MissedPolicy int
+
+const (
+	// MissedSkip drops missed ticks, the job runs once.
+	MissedSkip MissedPolicy = iota
+	// MissedRunAll runs the job for every missed tick, subject to OverlapPolicy.
+	MissedRunAll
+)
+
+// PeriodicSpec ...
+type PeriodicSpec struct {
+	Schedule            Schedule      // required, see Every and ParseCron
+	Jitter              time.Duration // random delay [0, Jitter) of each tick, keep it below interval
+	Overlap             OverlapPolicy
+	Missed              MissedPolicy
+	KillTimeoutProvider func(ctx context.Context) time.Duration
+}
+
+// Periodic runs job on schedule.
+// Job is a Worker of the run: it gets graceful context to finish on shutdown
+// and kill context which is done when it is abandoned. Done waits for running jobs.
+// Job errors do not stop Periodic, they are joined and reported by Err, see SetFailFast.
+// Periodic is a Service.
+type Periodic struct {
+	StartStopper
+
+	spec PeriodicSpec
+	job  Worker
+
+	mu      sync.Mutex // guards fields below
+	running int
+	queued  bool
+}
+
+// NewPeriodic ...
+// Invalid spec is reported by Start.
+func NewPeriodic(
+	ctx context.Context,
+	spec PeriodicSpec,
+	job Worker,
+) *Periodic {
+	periodic := &Periodic{
+		spec: spec,
+		job:  job,
+	}
+
+	_ = periodic.StartStopper.Init(ctx, spec.KillTimeoutProvider)
+
+	return periodic
+}
+
+// Start schedules the job.
+// Blocks until the run is done and running jobs returned. Returns Err.
+// Returns ErrInvalidOption if spec is invalid.
+func (periodic *Periodic) Start(ctx context.Context, readyCh chan<- error) error {
+	err := periodic.StartStopper.InitNotify(ctx, readyCh, nil)
+	if err != nil {
+		return err
+	}
+
+	_, _, done, err := periodic.StartStopper.StartWorkers(ctx, readyCh, periodic.validate, periodic.schedule)
+	if err != nil {
+		return err
+	}
+
+	<-done
+
+	return periodic.Err()
+}
+
+// Running returns number of running jobs.
+func (periodic *Periodic) Running() int {
+	return WithMutex1(&periodic.mu, func() int {
+		return periodic.running
+	})
+}
+
+func (periodic *Periodic) validate() error {
+	spec := periodic.spec
+
+	switch schedule := spec.Schedule.(type) {
+	case nil:
+		return JoinErrors(errInvalidOption, errors.New("periodic schedule is nil"))
+	case everySchedule:
+		if schedule.interval <= 0 {
+			return JoinErrors(errInvalidOption, errors.New("periodic interval is not positive"))
+		}
+	}
+
+	if spec.Jitter < 0 {
+		return JoinErrors(errInvalidOption, errors.New("periodic jitter is negative"))
+	}
+	if periodic.job == nil {
+		return JoinErrors(errInvalidOption, errors.New("periodic job is nil"))
+	}
+
+	WithMutex(&periodic.mu, func() {
+		periodic.queued = false
+	})
+	return nil
+}
+
+// schedule worker triggers the job until graceful shutdown begins.
+func (periodic *Periodic) schedule(ctx context.Context, _ context.Context) error {
+	clock := periodic.getClock()
+	schedule := periodic.spec.Schedule
+
+	next := schedule.Next(clock.Now())
+
+	for !next.IsZero() {
+		var jitter time.Duration
+		if periodic.spec.Jitter > 0 {
+			jitter = time.Duration(rand.Int63n(int64(periodic.spec.Jitter)))
+		}
+
+		wake := make(chan struct{})
+		timer := clock.AfterFunc(next.Sub(clock.Now())+jitter, func() { close(wake) })
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+
+		case <-wake:
+		}
+
+		// ticks due before the timer should have fired are missed
+		due := clock.Now().Add(-jitter)
+		runs := 1
+		for next = schedule.Next(next); !next.IsZero() && !next.After(due); next = schedule.Next(next) {
+			if periodic.spec.Missed == MissedRunAll {
+				runs++
+			}
+		}
+
+		for i := 0; i < runs; i++ {
+			periodic.trigger()
+		}
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+// trigger the job according to OverlapPolicy.
+func (periodic *Periodic) trigger() {
+	launch := WithMutex1(&periodic.mu, func() bool {
+		if periodic.running > 0 && periodic.spec.Overlap != OverlapAllow {
+			if periodic.spec.Overlap == OverlapQueue {
+				periodic.queued = true
+			}
+			return false
+		}
+
+		periodic.running++
+		return true
+	})
+
+	if !launch {
+		return
+	}
+
+	err := periodic.Go(periodic.run)
+	if err != nil {
+		// run is done already
+		WithMutex(&periodic.mu, func() {
+			periodic.running--
+		})
+	}
+}
+
+// run the job, then queued one unless shutting down.
+func (periodic *Periodic) run(ctx context.Context, killCtx context.Context) error {
+	var errs []error
+
+	finished := false
+	defer func() {
+		if !finished {
+			// job panicked, recovered by the run
+			WithMutex(&periodic.mu, func() {
+				periodic.running--
+			})
+		}
+	}()
+
+	for {
+		err := periodic.job(ctx, killCtx)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		again := WithMutex1(&periodic.mu, func() bool {
+			if periodic.queued && ctx.Err() == nil {
+				periodic.queued = false
+				return true
+			}
+			periodic.running--
+			return false
+		})
+
+		if !again {
+			finished = true
+			return JoinErrors(errs...)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package startstopper_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/Darigaaz/startstopper/v3/startstoppertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lateClock fires the next timer late by the stored duration.
+type lateClock struct {
+	*startstoppertest.FakeClock
+	late atomic.Int64
+}
+
+func (clock *lateClock) AfterFunc(d time.Duration, f func()) startstopper.Timer {
+	return clock.FakeClock.AfterFunc(d, func() {
+		clock.FakeClock.Advance(time.Duration(clock.late.Swap(0)))
+		f()
+	})
+}
+
+// tick advances clock to the next tick, waits for the scheduler to be ready first.
+func tick(t *testing.T, clock *startstoppertest.FakeClock, d time.Duration) {
+	t.Helper()
+
+	require.NoError(t, clock.BlockUntil(t.Context(), 1))
+	clock.Advance(d)
+}
+
+func TestPeriodic(t *testing.T) {
+	t.Run("runs on interval", func(t *testing.T) {
+		clock := startstoppertest.NewFakeClock(time.Now())
+		var runs atomic.Int32
+
+		periodic := startstopper.NewPeriodic(t.Context(), startstopper.PeriodicSpec{
+			Schedule: startstopper.Every(time.Second),
+		}, func(_ context.Context, _ context.Context) error {
+			runs.Add(1)
+			return nil
+		})
+		periodic.SetClock(clock)
+
+		result := startService(t, periodic)
+
+		// SUT
+		for i := int32(1); i <= 3; i++ {
+			tick(t, clock, time.Second)
+			assert.Eventually(t, func() bool { return runs.Load() == i }, time.Second, time.Millisecond)
+		}
+
+		periodic.Close()
+		require.NoError(t, <-result)
+	})
+
+	t.Run("jitter does not drift", func(t *testing.T) {
+		clock := startstoppertest.NewFakeClock(time.Now())
+		var runs atomic.Int32
+
+		periodic := startstopper.NewPeriodic(t.Context(), startstopper.PeriodicSpec{
+			Schedule: startstopper.Every(time.Second),
+			Jitter:   100 * time.Millisecond,
+			Overlap:  startstopper.OverlapAllow,
+		}, func(_ context.Context, _ context.Context) error {
+			runs.Add(1)
+			return nil
+		})
+		periodic.SetClock(clock)
+
+		result := startService(t, periodic)
+
+		// SUT
+		for i := 0; i < 101; i++ {
+			tick(t, clock, 100*time.Millisecond)
+		}
+		assert.Eventually(t, func() bool { return runs.Load() == 10 }, time.Second, time.Millisecond)
+
+		periodic.Close()
+		require.NoError(t, <-result)
+	})
+
+	t.Run("job finishes on graceful shutdown", func(t *testing.T) {
+		clock := startstoppertest.NewFakeClock(time.Now())
+		started := make(chan struct{})
+		var finished atomic.Bool
+
+		periodic := startstopper.NewPeriodic(t.Context(), startstopper.PeriodicSpec{
+			Schedule: startstopper.Every(time.Second),
+		}, func(ctx context.Context, _ context.Context) error {
+			close(started)
+			<-ctx.Done()
+			finished.Store(true)
+			return nil
+		})
+		periodic.SetClock(clock)
+
+		result := startService(t, periodic)
+		tick(t, clock, time.Second)
+		<-started
+
+		// SUT
+		periodic.Close()
+
+		require.NoError(t, <-result)
+		assert.True(t, finished.Load())
+		assert.ErrorIs(t, periodic.Cause(), startstopper.ErrClosed)
+	})
+
+	t.Run("abandoned job gets kill context", func(t *testing.T) {
+		started := make(chan struct{})
+
+		periodic := startstopper.NewPeriodic(t.Context(), startstopper.PeriodicSpec{
+			Schedule:            startstopper.Every(time.Millisecond),
+			KillTimeoutProvider: func(context.Context) time.Duration { return time.Millisecond },
+		}, func(_ context.Context, killCtx context.Context) error {
+			close(started)
+			// ignore graceful shutdown
+			<-killCtx.Done()
+			return context.Cause(killCtx)
+		})
+
+		result := startService(t, periodic)
+		<-started
+
+		// SUT
+		periodic.Close()
+
+		require.ErrorIs(t, <-result, startstopper.ErrKillTimeout)
+		assert.ErrorIs(t, periodic.Cause(), startstopper.ErrKillTimeout)
+	})
+
+	t.Run("invalid spec", func(t *testing.T) {
+		periodic := startstopper.NewPeriodic(t.Context(), startstopper.PeriodicSpec{}, func(context.Context, context.Context) error {
+			return nil
+		})
+
+		// SUT
+		err := periodic.Start(t.Context(), nil)
+		require.ErrorIs(t, err, startstopper.ErrInvalidOption)
+	})
+}
+
+func TestPeriodic_Overlap(t *testing.T) {
+	tests := []struct {
+		name    string
+		overlap startstopper.OverlapPolicy
+		running int
+		runs    int32
+	}{
+		{name: "skip", overlap: startstopper.OverlapSkip, running: 1, runs: 1},
+		{name: "queue", overlap: startstopper.OverlapQueue, running: 1, runs: 2},
+		{name: "allow", overlap: startstopper.OverlapAllow, running: 3, runs: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := startstoppertest.NewFakeClock(time.Now())
+			gate := make(chan struct{})
+			var runs atomic.Int32
+
+			periodic := startstopper.NewPeriodic(t.Context(), startstopper.PeriodicSpec{
+				Schedule: startstopper.Every(time.Second),
+				Overlap:  tt.overlap,
+			}, func(_ context.Context, _ context.Context) error {
+				runs.Add(1)
+				<-gate
+				return nil
+			})
+			periodic.SetClock(clock)
+
+			result := startService(t, periodic)
+
+			// SUT
+			for i := 0; i < 3; i++ {
+				tick(t, clock, time.Second)
+			}
+			require.NoError(t, clock.BlockUntil(t.Context(), 1))
+			assert.Equal(t, tt.running, periodic.Running())
+
+			close(gate)
+			assert.Eventually(t, func() bool { return periodic.Running() == 0 }, time.Second, time.Millisecond)
+			assert.Equal(t, tt.runs, runs.Load())
+
+			periodic.Close()
+			require.NoError(t, <-result)
+		})
+	}
+}
+
+func TestPeriodic_Missed(t *testing.T) {
+	tests := []struct {
+		name   string
+		missed startstopper.MissedPolicy
+		runs   int32
+	}{
+		{name: "skip", missed: startstopper.MissedSkip, runs: 1},
+		{name: "run all", missed: startstopper.MissedRunAll, runs: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &lateClock{FakeClock: startstoppertest.NewFakeClock(time.Now())}
+			var runs atomic.Int32
+
+			periodic := startstopper.NewPeriodic(t.Context(), startstopper.PeriodicSpec{
+				Schedule: startstopper.Every(time.Second),
+				Overlap:  startstopper.OverlapAllow,
+				Missed:   tt.missed,
+			}, func(_ context.Context, _ context.Context) error {
+				runs.Add(1)
+				return nil
+			})
+			periodic.SetClock(clock)
+
+			result := startService(t, periodic)
+
+			// SUT: the first tick fires 2.5s late
+			clock.late.Store(int64(2500 * time.Millisecond))
+			tick(t, clock.FakeClock, time.Second)
+
+			assert.Eventually(t, func() bool { return runs.Load() == tt.runs }, time.Second, time.Millisecond)
+
+			// schedule is kept
+			tick(t, clock.FakeClock, 500*time.Millisecond)
+			assert.Eventually(t, func() bool { return runs.Load() == tt.runs+1 }, time.Second, time.Millisecond)
+
+			periodic.Close()
+			require.NoError(t, <-result)
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package startstopper
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// PoolScaleIntervalDefault ...
+	PoolScaleIntervalDefault = time.Second
+)
+
+// Handler processes a single item.
+// ctx is the kill context, handler should return as soon as it is done.
+type Handler[T any] func(ctx context.Context, item T) error
+
+// PoolSpec ...
+// Pool scales between MinWorkers and MaxWorkers, see Pool.
+type PoolSpec struct {
+	MinWorkers          int           // zero means 1
+	MaxWorkers          int           // less than MinWorkers means MinWorkers, autoscaling is disabled then
+	ScaleInterval       time.Duration // zero means PoolScaleIntervalDefault
+	ScaleUpQueueDepth   int           // scale up when more items are buffered in input channel
+	ScaleUpLatency      time.Duration // scale up when average handler latency exceeds, zero disables
+	KillTimeoutProvider func(ctx context.Context) time.Duration
+}
+
+// Pool runs workers consuming items from input channel with handler.
+// On graceful shutdown workers drain items buffered in input channel, on kill they return immediately.
+// The run completes when input channel is closed and drained.
+// Each ScaleInterval one worker is added if input channel buffers more than ScaleUpQueueDepth items
+// or average handler latency exceeds ScaleUpLatency, and one idle worker is removed
+// if input channel is empty and average handler latency is below half of ScaleUpLatency.
+// Handler errors are joined per worker and reported by Err.
+// Pool is a Service.
+type Pool[T any] struct {
+	StartStopper
+
+	spec    PoolSpec
+	in      <-chan T
+	handler Handler[T]
+
+	workers atomic.Int32  // running consumers
+	ids     atomic.Int32  // consumer ids, for errors
+	quit    chan struct{} // idle consumer receives to scale down
+
+	mu        sync.Mutex // guards fields below
+	handled   int
+	latency   time.Duration // sum of handled items latency
+	exhausted chan struct{} // closes when input channel is closed
+	closeOnce *sync.Once
+}
+
+// NewPool ...
+func NewPool[T any](
+	ctx context.Context,
+	spec PoolSpec,
+	in <-chan T,
+	handler Handler[T],
+) *Pool[T] {
+	if spec.MinWorkers <= 0 {
+		spec.MinWorkers = 1
+	}
+	if spec.MaxWorkers < spec.MinWorkers {
+		spec.MaxWorkers = spec.MinWorkers
+	}
+	if spec.ScaleInterval <= 0 {
+		spec.ScaleInterval = PoolScaleIntervalDefault
+	}
+
+	pool := &Pool[T]{
+		spec:    spec,
+		in:      in,
+		handler: handler,
+		quit:    make(chan struct{}),
+	}
+
+	_ = pool.StartStopper.Init(ctx, spec.KillTimeoutProvider)
+
+	return pool
+}
+
+// Start MinWorkers consumers and autoscaler.
+// Blocks until the run is done. Returns Err.
+func (pool *Pool[T]) Start(ctx context.Context, readyCh chan<- error) error {
+	err := pool.StartStopper.InitNotify(ctx, readyCh, nil)
+	if err != nil {
+		return err
+	}
+
+	workers := make([]Worker, 0, pool.spec.MinWorkers+1)
+	for i := 0; i < pool.spec.MinWorkers; i++ {
+		workers = append(workers, pool.consume)
+	}
+	if pool.spec.MaxWorkers > pool.spec.MinWorkers {
+		workers = append(workers, pool.autoscale)
+	}
+
+	_, _, done, err := pool.StartStopper.StartWorkers(ctx, readyCh, pool.reset, workers...)
+	if err != nil {
+		return err
+	}
+
+	<-done
+
+	return pool.Err()
+}
+
+// Workers returns number of running consumers.
+func (pool *Pool[T]) Workers() int {
+	return int(pool.workers.Load())
+}
+
+// reset stats of the previous run.
+func (pool *Pool[T]) reset() error {
+	WithMutex(&pool.mu, func() {
+		pool.handled = 0
+		pool.latency = 0
+		pool.exhausted = make(chan struct{})
+		pool.closeOnce = &sync.Once{}
+	})
+	return nil
+}
+
+func (pool *Pool[T]) consume(ctx context.Context, killCtx context.Context) error {
+	id := pool.ids.Add(1)
+	pool.workers.Add(1)
+	defer pool.workers.Add(-1)
+
+	var errs []error
+	handle := func(item T) {
+		clock := pool.getClock()
+		startedAt := clock.Now()
+		err := pool.handler(killCtx, item)
+		latency := clock.Now().Sub(startedAt)
+
+		WithMutex(&pool.mu, func() {
+			pool.handled++
+			pool.latency += latency
+		})
+
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	result := func() error {
+		if len(errs) == 0 {
+			return nil
+		}
+		return fmt.Errorf("pool worker %d: %w", id, JoinErrors(errs...))
+	}
+
+	ctxDone := ctx.Done()
+	killChan := killCtx.Done()
+
+	for {
+		// prioritize kill
+		select {
+		case <-killChan:
+			return result()
+		default:
+		}
+
+		select {
+		case <-killChan:
+			return result()
+
+		case <-ctxDone:
+			return pool.drain(killChan, handle, result)
+
+		case <-pool.quit:
+			return result()
+
+		case item, ok := <-pool.in:
+			if !ok {
+				pool.exhaust()
+				return result()
+			}
+			handle(item)
+		}
+	}
+}
+
+// drain buffered items until input channel is empty or kill.
+func (pool *Pool[T]) drain(killChan <-chan struct{}, handle func(item T), result func() error) error {
+	for {
+		select {
+		case <-killChan:
+			return result()
+		default:
+		}
+
+		select {
+		case <-killChan:
+			return result()
+
+		case item, ok := <-pool.in:
+			if !ok {
+				pool.exhaust()
+				return result()
+			}
+			handle(item)
+
+		default:
+			return result()
+		}
+	}
+}
+
+func (pool *Pool[T]) exhaust() {
+	WithMutex(&pool.mu, func() {
+		pool.closeOnce.Do(func() { close(pool.exhausted) })
+	})
+}
+
+func (pool *Pool[T]) autoscale(ctx context.Context, _ context.Context) error {
+	clock := pool.getClock()
+	exhausted := WithMutex1(&pool.mu, func() chan struct{} {
+		return pool.exhausted
+	})
+
+	for {
+		wake := make(chan struct{})
+		timer := clock.AfterFunc(pool.spec.ScaleInterval, func() { close(wake) })
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+
+		case <-exhausted:
+			timer.Stop()
+			return nil
+
+		case <-wake:
+		}
+
+		pool.scale()
+	}
+}
+
+// scale by one worker according to queue depth and average latency since the last call.
+func (pool *Pool[T]) scale() {
+	var latency time.Duration
+	WithMutex(&pool.mu, func() {
+		if pool.handled > 0 {
+			latency = pool.latency / time.Duration(pool.handled)
+		}
+		pool.handled = 0
+		pool.latency = 0
+	})
+
+	depth := len(pool.in)
+	workers := pool.Workers()
+	slow := pool.spec.ScaleUpLatency > 0 && latency > pool.spec.ScaleUpLatency
+	fast := pool.spec.ScaleUpLatency == 0 || latency <= pool.spec.ScaleUpLatency/2
+
+	switch {
+	case workers < pool.spec.MaxWorkers && (depth > pool.spec.ScaleUpQueueDepth || slow):
+		// fails if the run is done already
+		_ = pool.Go(pool.consume)
+
+	case workers > pool.spec.MinWorkers && depth == 0 && fast:
+		select {
+		case pool.quit <- struct{}{}:
+		default:
+			// no idle worker
+		}
+	}
+}
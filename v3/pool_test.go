@@ -0,0 +1,171 @@
+package startstopper_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/Darigaaz/startstopper/v3/startstoppertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool(t *testing.T) {
+	t.Run("completes when input is closed", func(t *testing.T) {
+		in := make(chan int)
+		var sum atomic.Int64
+
+		pool := startstopper.NewPool(t.Context(), startstopper.PoolSpec{MinWorkers: 3}, in,
+			func(_ context.Context, item int) error {
+				sum.Add(int64(item))
+				return nil
+			})
+
+		result := startService(t, pool)
+		assert.Eventually(t, func() bool { return pool.Workers() == 3 }, time.Second, time.Millisecond)
+
+		// SUT
+		for i := 1; i <= 100; i++ {
+			in <- i
+		}
+		close(in)
+
+		require.NoError(t, <-result)
+		assert.Equal(t, int64(5050), sum.Load())
+		assert.ErrorIs(t, pool.Cause(), startstopper.ErrCompleted)
+		assert.Equal(t, 0, pool.Workers())
+	})
+
+	t.Run("drains buffered items on close", func(t *testing.T) {
+		in := make(chan int, 5)
+		gate := make(chan struct{})
+		var handled atomic.Int32
+
+		pool := startstopper.NewPool(t.Context(), startstopper.PoolSpec{}, in,
+			func(_ context.Context, _ int) error {
+				<-gate
+				handled.Add(1)
+				return nil
+			})
+
+		result := startService(t, pool)
+		for i := 0; i < 5; i++ {
+			in <- i
+		}
+
+		// SUT
+		pool.CloseAsync()
+		close(gate)
+
+		require.NoError(t, <-result)
+		assert.Equal(t, int32(5), handled.Load())
+		assert.ErrorIs(t, pool.Cause(), startstopper.ErrClosed)
+	})
+
+	t.Run("kill stops immediately", func(t *testing.T) {
+		in := make(chan int, 5)
+		started := make(chan struct{}, 5)
+		var handled atomic.Int32
+
+		pool := startstopper.NewPool(t.Context(), startstopper.PoolSpec{}, in,
+			func(ctx context.Context, _ int) error {
+				started <- struct{}{}
+				<-ctx.Done()
+				handled.Add(1)
+				return ctx.Err()
+			})
+
+		result := startService(t, pool)
+		for i := 0; i < 5; i++ {
+			in <- i
+		}
+		<-started
+
+		// SUT
+		pool.KillAsync()
+
+		require.ErrorIs(t, <-result, context.Canceled)
+		assert.Equal(t, int32(1), handled.Load())
+		assert.Len(t, in, 4)
+	})
+
+	t.Run("collects handler errors", func(t *testing.T) {
+		errOdd := errors.New("odd item")
+		in := make(chan int)
+
+		pool := startstopper.NewPool(t.Context(), startstopper.PoolSpec{MinWorkers: 2}, in,
+			func(_ context.Context, item int) error {
+				if item%2 == 1 {
+					return errOdd
+				}
+				return nil
+			})
+
+		result := startService(t, pool)
+		for i := 0; i < 10; i++ {
+			in <- i
+		}
+
+		// SUT
+		close(in)
+
+		err := <-result
+		require.ErrorIs(t, err, errOdd)
+		assert.Contains(t, err.Error(), "pool worker")
+		assert.Equal(t, err, pool.Err())
+	})
+}
+
+func TestPool_Autoscale(t *testing.T) {
+	clock := startstoppertest.NewFakeClock(time.Now())
+	in := make(chan int, 10)
+	gate := make(chan struct{})
+
+	pool := startstopper.NewPool(t.Context(), startstopper.PoolSpec{
+		MinWorkers:    1,
+		MaxWorkers:    3,
+		ScaleInterval: time.Second,
+	}, in, func(_ context.Context, _ int) error {
+		<-gate
+		return nil
+	})
+	pool.SetClock(clock)
+
+	result := startService(t, pool)
+	require.NoError(t, clock.BlockUntil(t.Context(), 1))
+
+	for i := 0; i < 10; i++ {
+		in <- i
+	}
+
+	// SUT: scales up by one worker per interval up to MaxWorkers
+	for workers := 2; workers <= 3; workers++ {
+		require.NoError(t, clock.BlockUntil(t.Context(), 1))
+		clock.Advance(time.Second)
+		assert.Eventually(t, func() bool { return pool.Workers() == workers }, time.Second, time.Millisecond)
+	}
+
+	require.NoError(t, clock.BlockUntil(t.Context(), 1))
+	clock.Advance(time.Second)
+	assert.Equal(t, 3, pool.Workers())
+
+	// SUT: idle workers are removed down to MinWorkers
+	close(gate)
+	assert.Eventually(t, func() bool { return len(in) == 0 }, time.Second, time.Millisecond)
+
+	for pool.Workers() > 1 {
+		require.NoError(t, clock.BlockUntil(t.Context(), 1))
+		clock.Advance(time.Second)
+		time.Sleep(time.Millisecond)
+	}
+
+	require.NoError(t, clock.BlockUntil(t.Context(), 1))
+	clock.Advance(time.Second)
+	assert.Equal(t, 1, pool.Workers())
+
+	pool.Close()
+	require.NoError(t, <-result)
+}
@@ -0,0 +1,269 @@
+package startstopper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ProbeTimeoutDefault ...
+	ProbeTimeoutDefault = time.Second
+)
+
+var (
+	ErrNotReady = errors.New("not ready")
+	errNotReady = NewErrorCode(ErrNotReady, "STARTSTOPPER_ERR_NOT_READY")
+
+	ErrNotStarted = errors.New("not started")
+	errNotStarted = NewErrorCode(ErrNotStarted, "STARTSTOPPER_ERR_NOT_STARTED")
+
+	ErrStopped = errors.New("stopped")
+	errStopped = NewErrorCode(ErrStopped, "STARTSTOPPER_ERR_STOPPED")
+)
+
+// ProbeKind ...
+type ProbeKind int
+
+const (
+	ProbeLiveness ProbeKind = iota
+	ProbeReadiness
+	ProbeStartup
+)
+
+var probeKindNames = map[ProbeKind]string{
+	ProbeLiveness:  "livez",
+	ProbeReadiness: "readyz",
+	ProbeStartup:   "startupz",
+}
+
+// String ...
+func (kind ProbeKind) String() string {
+	if name, ok := probeKindNames[kind]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Check returns nil if healthy.
+type Check func(ctx context.Context) error
+
+// ProbeStatus ...
+type ProbeStatus struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckStatus `json:"checks,omitempty"`
+}
+
+// CheckStatus ...
+// Error and Latency are rendered in verbose mode.
+type CheckStatus struct {
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+	Latency string `json:"latency,omitempty"`
+}
+
+// Probes aggregates named liveness, readiness and startup checks.
+// The zero value is not ready to use, call NewProbes.
+type Probes struct {
+	timeout time.Duration
+
+	mu     sync.Mutex
+	checks map[ProbeKind][]*probeCheck
+}
+
+type probeCheck struct {
+	name  string
+	check Check
+}
+
+// result of a single check run
+type checkResult struct {
+	name    string
+	err     error
+	latency time.Duration
+}
+
+// NewProbes ...
+// Each check is limited by timeout, zero means ProbeTimeoutDefault.
+// Check which ignores its context is reported failed on timeout and left running.
+func NewProbes(timeout time.Duration) *Probes {
+	if timeout <= 0 {
+		timeout = ProbeTimeoutDefault
+	}
+
+	return &Probes{
+		timeout: timeout,
+		checks:  make(map[ProbeKind][]*probeCheck),
+	}
+}
+
+// AddCheck registers named check of kind.
+// Returns ErrInvalidOption if check of kind with the same name is registered already.
+func (probes *Probes) AddCheck(kind ProbeKind, name string, check Check) error {
+	return WithMutex1(&probes.mu, func() error {
+		for _, registered := range probes.checks[kind] {
+			if registered.name == name {
+				return JoinErrors(errInvalidOption, fmt.Errorf("%s check %q is duplicated", kind, name))
+			}
+		}
+
+		probes.checks[kind] = append(probes.checks[kind], &probeCheck{name: name, check: check})
+		return nil
+	})
+}
+
+// AddService registers checks derived from StartStopper state:
+// startup passes once the current (or last) run reached running, it fails if startFn failed, readiness passes while running (fails while paused and as soon as graceful shutdown begins),
+// liveness fails once stopped.
+// Returns ErrInvalidOption for each kind which has check with the same name already, see AddCheck.
+func (probes *Probes) AddService(name string, startStopper *StartStopper) error {
+	startupErr := probes.AddCheck(ProbeStartup, name, func(_ context.Context) error {
+		if !startStopper.started() {
+			return errNotStarted
+		}
+		return nil
+	})
+
+	readinessErr := probes.AddCheck(ProbeReadiness, name, func(_ context.Context) error {
+		if startStopper.State() != StateRunning {
+			return errNotReady
+		}
+		return nil
+	})
+
+	livenessErr := probes.AddCheck(ProbeLiveness, name, func(_ context.Context) error {
+		if startStopper.State() == StateStopped {
+			return errStopped
+		}
+		return nil
+	})
+
+	return JoinErrors(startupErr, readinessErr, livenessErr)
+}
+
+// Check runs all checks of kind concurrently.
+// Returns joined errors of failed checks.
+func (probes *Probes) Check(ctx context.Context, kind ProbeKind) error {
+	var errs []error
+
+	for _, result := range probes.run(ctx, kind) {
+		if result.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", result.name, result.err))
+		}
+	}
+
+	return JoinErrors(errs...)
+}
+
+// Status runs all checks of kind and reports per check status.
+func (probes *Probes) Status(ctx context.Context, kind ProbeKind, verbose bool) ProbeStatus {
+	status := ProbeStatus{
+		Status: "ok",
+		Checks: make(map[string]CheckStatus),
+	}
+
+	for _, result := range probes.run(ctx, kind) {
+		checkStatus := CheckStatus{Status: "ok"}
+
+		err := result.err
+		if err != nil {
+			checkStatus.Status = "failed"
+			status.Status = "failed"
+		}
+
+		if verbose {
+			if err != nil {
+				checkStatus.Error = err.Error()
+			}
+			checkStatus.Latency = result.latency.String()
+		}
+
+		status.Checks[result.name] = checkStatus
+	}
+
+	return status
+}
+
+// Handler serves /livez, /readyz and /startupz (matched by path suffix).
+// Responds 200 or 503 with JSON ProbeStatus, add ?verbose to see errors and latencies.
+func (probes *Probes) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for kind, name := range probeKindNames {
+			if strings.HasSuffix(r.URL.Path, name) {
+				probes.KindHandler(kind).ServeHTTP(w, r)
+				return
+			}
+		}
+
+		http.NotFound(w, r)
+	})
+}
+
+// KindHandler serves checks of kind.
+func (probes *Probes) KindHandler(kind ProbeKind) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, verbose := r.URL.Query()["verbose"]
+
+		status := probes.Status(r.Context(), kind, verbose)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+
+		if status.Status != "ok" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		_ = json.NewEncoder(w).Encode(status)
+	})
+}
+
+// run checks of kind concurrently, results are sorted by name.
+func (probes *Probes) run(ctx context.Context, kind ProbeKind) []checkResult {
+	checks := WithMutex1(&probes.mu, func() []*probeCheck {
+		checks := append([]*probeCheck(nil), probes.checks[kind]...)
+		sort.SliceStable(checks, func(i, j int) bool { return checks[i].name < checks[j].name })
+		return checks
+	})
+
+	results := make([]checkResult, len(checks))
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check *probeCheck) {
+			defer wg.Done()
+			results[i] = check.run(ctx, probes.timeout)
+		}(i, check)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func (check *probeCheck) run(ctx context.Context, timeout time.Duration) checkResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	begin := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- check.check(ctx)
+	}()
+
+	result := checkResult{name: check.name}
+	select {
+	case result.err = <-errCh:
+	case <-ctx.Done():
+		// dont wait for check ignoring ctx
+		result.err = ctx.Err()
+	}
+	result.latency = time.Since(begin)
+
+	return result
+}
@@ -0,0 +1,172 @@
+package startstopper_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func probe(t *testing.T, handler http.Handler, target string) (int, startstopper.ProbeStatus) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+	var status startstopper.ProbeStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	return rec.Code, status
+}
+
+func TestProbes_Service(t *testing.T) {
+	startStopper := startstopper.New(t.Context(), nil)
+
+	probes := startstopper.NewProbes(0)
+	require.NoError(t, probes.AddService("srv", startStopper))
+	handler := probes.Handler()
+
+	code, _ := probe(t, handler, "/startupz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	code, _ = probe(t, handler, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	code, _ = probe(t, handler, "/livez")
+	assert.Equal(t, http.StatusOK, code)
+
+	cleanupDone, doneFn := startstopper.ChanCloser(nil)
+	_, _, done, err := startStopper.Start(t.Context(), cleanupDone, nil, nil)
+	require.NoError(t, err)
+
+	code, _ = probe(t, handler, "/startupz")
+	assert.Equal(t, http.StatusOK, code)
+	code, status := probe(t, handler, "/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", status.Checks["srv"].Status)
+
+	// SUT: readiness drops as soon as graceful shutdown begins
+	startStopper.CloseAsync()
+
+	code, status = probe(t, handler, "/readyz?verbose")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "failed", status.Status)
+	assert.Equal(t, startstopper.ErrNotReady.Error(), status.Checks["srv"].Error)
+	code, _ = probe(t, handler, "/livez")
+	assert.Equal(t, http.StatusOK, code)
+
+	doneFn()
+	<-done
+
+	code, _ = probe(t, handler, "/livez")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	code, _ = probe(t, handler, "/startupz")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestProbes_StartupFailed(t *testing.T) {
+	startStopper := startstopper.New(t.Context(), nil)
+
+	probes := startstopper.NewProbes(0)
+	require.NoError(t, probes.AddService("srv", startStopper))
+
+	// SUT
+	_, _, _, err := startStopper.Start(t.Context(), nil, nil, func() error { return errors.New("boom") })
+	require.Error(t, err)
+
+	require.Equal(t, startstopper.StateStopped, startStopper.State())
+	require.ErrorIs(t, probes.Check(t.Context(), startstopper.ProbeStartup), startstopper.ErrNotStarted)
+	assert.True(t, startstopper.MatchErrorCodes(probes.Check(t.Context(), startstopper.ProbeStartup), "STARTSTOPPER_ERR_NOT_STARTED"))
+}
+
+func TestProbes_Checks(t *testing.T) {
+	errDB := errors.New("db unreachable")
+
+	probes := startstopper.NewProbes(0)
+	require.NoError(t, probes.AddCheck(startstopper.ProbeReadiness, "cache", func(context.Context) error { return nil }))
+	require.NoError(t, probes.AddCheck(startstopper.ProbeReadiness, "db", func(context.Context) error { return errDB }))
+
+	// SUT
+	err := probes.Check(t.Context(), startstopper.ProbeReadiness)
+	require.ErrorIs(t, err, errDB)
+	require.NoError(t, probes.Check(t.Context(), startstopper.ProbeLiveness))
+
+	code, status := probe(t, probes.Handler(), "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, startstopper.CheckStatus{Status: "ok"}, status.Checks["cache"])
+	assert.Equal(t, startstopper.CheckStatus{Status: "failed"}, status.Checks["db"])
+
+	code, status = probe(t, probes.Handler(), "/readyz?verbose")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, errDB.Error(), status.Checks["db"].Error)
+	assert.NotEmpty(t, status.Checks["db"].Latency)
+
+	rec := httptest.NewRecorder()
+	probes.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestProbes_Duplicate(t *testing.T) {
+	probes := startstopper.NewProbes(0)
+	require.NoError(t, probes.AddCheck(startstopper.ProbeReadiness, "db", func(context.Context) error { return nil }))
+
+	// SUT
+	err := probes.AddCheck(startstopper.ProbeReadiness, "db", func(context.Context) error { return errors.New("shadowed") })
+	require.ErrorIs(t, err, startstopper.ErrInvalidOption)
+	require.NoError(t, probes.Check(t.Context(), startstopper.ProbeReadiness))
+
+	// names are unique per kind
+	require.NoError(t, probes.AddCheck(startstopper.ProbeLiveness, "db", func(context.Context) error { return nil }))
+
+	err = probes.AddService("db", startstopper.New(t.Context(), nil))
+	require.ErrorIs(t, err, startstopper.ErrInvalidOption)
+	assert.ErrorContains(t, err, `readyz check "db" is duplicated`)
+	assert.ErrorContains(t, err, `livez check "db" is duplicated`)
+	require.Error(t, probes.Check(t.Context(), startstopper.ProbeStartup), "startup check is added")
+}
+
+func TestProbes_Timeout(t *testing.T) {
+	gate := make(chan struct{})
+	t.Cleanup(func() { close(gate) })
+
+	probes := startstopper.NewProbes(10 * time.Millisecond)
+	require.NoError(t, probes.AddCheck(startstopper.ProbeLiveness, "stuck", func(context.Context) error {
+		// ignores ctx
+		<-gate
+		return nil
+	}))
+
+	// SUT
+	err := probes.Check(t.Context(), startstopper.ProbeLiveness)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestProbes_Concurrent(t *testing.T) {
+	probes := startstopper.NewProbes(0)
+	require.NoError(t, probes.AddCheck(startstopper.ProbeReadiness, "ctx", func(ctx context.Context) error {
+		time.Sleep(time.Millisecond)
+		return ctx.Err()
+	}))
+
+	canceled, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	// SUT: results of concurrent runs dont mix
+	errs := make(chan error, 20)
+	for i := 0; i < 10; i++ {
+		go func() { errs <- probes.Check(t.Context(), startstopper.ProbeReadiness) }()
+		go func() { errs <- probes.Check(canceled, startstopper.ProbeReadiness) }()
+	}
+
+	var failed int
+	for i := 0; i < 20; i++ {
+		if err := <-errs; err != nil {
+			failed++
+		}
+	}
+	assert.Equal(t, 10, failed)
+}
@@ -0,0 +1,218 @@
+package startstopper
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrRestarted = errors.New("restarted")
+	errRestarted = NewErrorCode(ErrRestarted, "STARTSTOPPER_ERR_RESTARTED")
+
+	ErrNotRestartable = errors.New("not restartable")
+	errNotRestartable = NewErrorCode(ErrNotRestartable, "STARTSTOPPER_ERR_NOT_RESTARTABLE")
+
+	ErrReload = errors.New("reload failed, rolled back")
+	errReload = NewErrorCode(ErrReload, "STARTSTOPPER_ERR_RELOAD")
+)
+
+// runKind is how the run was started, Restart continues with the same kind.
+type runKind int
+
+const (
+	runWorkers    runKind = iota // StartWorkers
+	runCleanup                   // Start
+	runCleanupErr                // StartErr
+)
+
+// runSpec of the last successful run, used by Restart
+type runSpec struct {
+	kind    runKind
+	startFn func() error
+	workers []Worker
+}
+
+// Restart gracefully stops the current run with ErrRestarted cause and starts the next one
+// with the same workers, Start from other goroutines fails with ErrStart meanwhile.
+// startFn (optional) replaces startFn of the last run, nil keeps it.
+// Restart continues runs started by StartWorkers (workers added by Go are not restarted),
+// see RestartStart and RestartStartErr for the other forms. Returns ErrNotRestartable otherwise.
+// Close or Kill during Restart stop the current run and refuse the next one.
+// Returns gracefulContext, killContext, doneChan, error of the next run like Start.
+func (startStopper *StartStopper) Restart(
+	ctx context.Context,
+	startFn func() error, // optional
+) (
+	context.Context, // graceful context
+	context.Context, // killCtx context
+	<-chan struct{}, // done chan
+	error,
+) {
+	return startStopper.restart(ctx, runWorkers, nil, startFn, false)
+}
+
+// RestartStart like Restart, continues a run started by Start.
+// Current run is done once its cleanupDoneChan is closed, the next run waits for cleanupDoneChan.
+// E.g. reload on SIGHUP instead of Close followed by Start, which another Start can sneak in between.
+func (startStopper *StartStopper) RestartStart(
+	ctx context.Context,
+	cleanupDoneChan <-chan struct{},
+	startFn func() error, // optional
+) (
+	context.Context, // graceful context
+	context.Context, // killCtx context
+	<-chan struct{}, // done chan
+	error,
+) {
+	return startStopper.restart(ctx, runCleanup, waitCleanup(cleanupDoneChan), startFn, false)
+}
+
+// RestartStartErr like Restart, continues a run started by StartErr.
+// The next run reports errors to cleanupErrChan.
+func (startStopper *StartStopper) RestartStartErr(
+	ctx context.Context,
+	cleanupErrChan <-chan error,
+	startFn func() error, // optional
+) (
+	context.Context, // graceful context
+	context.Context, // killCtx context
+	<-chan struct{}, // done chan
+	error,
+) {
+	return startStopper.restart(ctx, runCleanupErr, waitCleanupErr(cleanupErrChan), startFn, false)
+}
+
+// Reload like Restart, but if startFn fails the run is restarted with startFn of the last run,
+// known to be good. Then contexts of the rolled back run are returned
+// with ErrReload joined with startFn error.
+// If the rollback fails too, its error is joined as well and the service is stopped.
+func (startStopper *StartStopper) Reload(
+	ctx context.Context,
+	startFn func() error,
+) (
+	context.Context, // graceful context
+	context.Context, // killCtx context
+	<-chan struct{}, // done chan
+	error,
+) {
+	return startStopper.restart(ctx, runWorkers, nil, startFn, true)
+}
+
+// ReloadStart like Reload, continues a run started by Start, see RestartStart.
+// The rolled back run waits for cleanupDoneChan as well.
+func (startStopper *StartStopper) ReloadStart(
+	ctx context.Context,
+	cleanupDoneChan <-chan struct{},
+	startFn func() error,
+) (
+	context.Context, // graceful context
+	context.Context, // killCtx context
+	<-chan struct{}, // done chan
+	error,
+) {
+	return startStopper.restart(ctx, runCleanup, waitCleanup(cleanupDoneChan), startFn, true)
+}
+
+// ReloadStartErr like Reload, continues a run started by StartErr, see RestartStartErr.
+func (startStopper *StartStopper) ReloadStartErr(
+	ctx context.Context,
+	cleanupErrChan <-chan error,
+	startFn func() error,
+) (
+	context.Context, // graceful context
+	context.Context, // killCtx context
+	<-chan struct{}, // done chan
+	error,
+) {
+	return startStopper.restart(ctx, runCleanupErr, waitCleanupErr(cleanupErrChan), startFn, true)
+}
+
+// restart the run of kind, with rollback to startFn of the last run if reload is set.
+func (startStopper *StartStopper) restart(
+	ctx context.Context,
+	kind runKind,
+	wait func() error,
+	startFn func() error,
+	reload bool,
+) (
+	context.Context,
+	context.Context,
+	<-chan struct{},
+	error,
+) {
+	spec, err := startStopper.beginRestart(kind)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer startStopper.endRestart()
+
+	if !reload {
+		if startFn == nil {
+			startFn = spec.startFn
+		}
+		return startStopper.start(ctx, wait, spec.workers, nil, startFn, kind, true)
+	}
+
+	gracefulCtx, killCtx, done, err := startStopper.start(ctx, wait, spec.workers, nil, startFn, kind, true)
+	if err == nil || !startStopper.canRollback() {
+		return gracefulCtx, killCtx, done, err
+	}
+
+	gracefulCtx, killCtx, done, rollbackErr := startStopper.start(ctx, wait, spec.workers, nil, spec.startFn, kind, true)
+	return gracefulCtx, killCtx, done, JoinErrors(errReload, err, rollbackErr)
+}
+
+// beginRestart reserves the lifecycle and begins graceful shutdown of the current run,
+// waits for it to be done.
+// Returns ErrNotRestartable if the last run is not of kind.
+func (startStopper *StartStopper) beginRestart(kind runKind) (*runSpec, error) {
+	var (
+		spec                  *runSpec
+		done                  <-chan struct{}
+		gracefulCtxCancelFunc context.CancelCauseFunc
+		gen                   uint64
+		err                   error
+	)
+
+	WithMutex(&startStopper.mu, func() {
+		WithMutex(&startStopper.cancelMu, func() {
+			switch {
+			case startStopper.initErr != nil:
+				err = startStopper.initErr
+			case startStopper.restarting:
+				err = errStart
+			case startStopper.runSpec == nil || startStopper.runSpec.kind != kind:
+				err = errNotRestartable
+			default:
+				spec = startStopper.runSpec
+				done = startStopper.done
+				gracefulCtxCancelFunc = startStopper.gracefulCtxCancelFunc
+				gen = startStopper.generation()
+				startStopper.restarting = true
+			}
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	gracefulCtxCancelFunc(errRestarted)
+	startStopper.setState(gen, StateStopping, StateStarting, StateRunning, StatePaused)
+	<-done
+
+	return spec, nil
+}
+
+func (startStopper *StartStopper) endRestart() {
+	WithMutex(&startStopper.cancelMu, func() {
+		startStopper.restarting = false
+		startStopper.pendingCause = nil
+	})
+}
+
+// canRollback unless Close or Kill was called during Reload.
+func (startStopper *StartStopper) canRollback() bool {
+	return WithMutex1(&startStopper.cancelMu, func() bool {
+		return startStopper.pendingCause == nil
+	})
+}
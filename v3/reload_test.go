@@ -0,0 +1,281 @@
+package startstopper_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drainingWorker blocks graceful shutdown until release is closed, reports draining.
+func drainingWorker(draining chan<- struct{}, release <-chan struct{}) startstopper.Worker {
+	return func(ctx context.Context, _ context.Context) error {
+		<-ctx.Done()
+		draining <- struct{}{}
+		<-release
+		return nil
+	}
+}
+
+func TestStartStopper_Restart(t *testing.T) {
+	t.Run("restarts workers", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		var starts, runs atomic.Int32
+		startFn := func() error {
+			starts.Add(1)
+			return nil
+		}
+		worker := func(ctx context.Context, killCtx context.Context) error {
+			runs.Add(1)
+			return waitGraceful(ctx, killCtx)
+		}
+
+		ctx, _, done, err := startStopper.StartWorkers(t.Context(), nil, startFn, worker)
+		require.NoError(t, err)
+
+		// SUT
+		nextCtx, _, nextDone, err := startStopper.Restart(t.Context(), nil)
+		require.NoError(t, err)
+
+		<-done
+		assert.ErrorIs(t, context.Cause(ctx), startstopper.ErrRestarted)
+		assert.NoError(t, nextCtx.Err())
+		assert.Equal(t, startstopper.StateRunning, startStopper.State())
+		assert.Equal(t, int32(2), starts.Load())
+		assert.Eventually(t, func() bool { return runs.Load() == 2 }, time.Second, time.Millisecond)
+
+		startStopper.Close()
+		<-nextDone
+		assert.ErrorIs(t, startStopper.Cause(), startstopper.ErrClosed)
+	})
+
+	t.Run("refuses Start meanwhile", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		draining := make(chan struct{}, 1)
+		release := make(chan struct{})
+
+		_, _, _, err := startStopper.StartWorkers(t.Context(), nil, nil, drainingWorker(draining, release))
+		require.NoError(t, err)
+
+		restarted := make(chan error, 1)
+		go func() {
+			_, _, _, err := startStopper.Restart(t.Context(), nil)
+			restarted <- err
+		}()
+		<-draining
+
+		// SUT
+		_, _, _, err = startStopper.Start(t.Context(), nil, nil, nil)
+		require.ErrorIs(t, err, startstopper.ErrStart)
+		_, _, _, err = startStopper.Restart(t.Context(), nil)
+		require.ErrorIs(t, err, startstopper.ErrStart)
+
+		close(release)
+		require.NoError(t, <-restarted)
+		assert.Equal(t, startstopper.StateRunning, startStopper.State())
+
+		startStopper.Close()
+	})
+
+	t.Run("close refuses next run", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		draining := make(chan struct{}, 1)
+		release := make(chan struct{})
+
+		_, _, _, err := startStopper.StartWorkers(t.Context(), nil, nil, drainingWorker(draining, release))
+		require.NoError(t, err)
+
+		restarted := make(chan error, 1)
+		go func() {
+			_, _, _, err := startStopper.Restart(t.Context(), nil)
+			restarted <- err
+		}()
+		<-draining
+
+		// SUT
+		startStopper.CloseAsync()
+
+		close(release)
+		require.ErrorIs(t, <-restarted, startstopper.ErrClosed)
+		assert.ErrorIs(t, startStopper.Cause(), startstopper.ErrClosed)
+		assert.Equal(t, startstopper.StateStopped, startStopper.State())
+
+		// remembered for Restart only
+		_, _, done, err := startStopper.Restart(t.Context(), nil)
+		require.NoError(t, err)
+		startStopper.Close()
+		<-done
+	})
+
+	t.Run("not restartable", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		// SUT
+		_, _, _, err := startStopper.Restart(t.Context(), nil)
+		require.ErrorIs(t, err, startstopper.ErrNotRestartable)
+
+		cleanupDone, doneFn := startstopper.ChanCloser(nil)
+		_, _, done, err := startStopper.Start(t.Context(), cleanupDone, nil, nil)
+		require.NoError(t, err)
+
+		_, _, _, err = startStopper.Restart(t.Context(), nil)
+		require.ErrorIs(t, err, startstopper.ErrNotRestartable)
+		_, _, _, err = startStopper.RestartStartErr(t.Context(), nil, nil)
+		require.ErrorIs(t, err, startstopper.ErrNotRestartable)
+		assert.Equal(t, startstopper.StateRunning, startStopper.State())
+
+		doneFn()
+		<-done
+	})
+
+	t.Run("restarts Start run", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		var starts atomic.Int32
+		startFn := func() error {
+			starts.Add(1)
+			return nil
+		}
+
+		// cleanup is done once graceful shutdown begins, like a caller of Start does
+		cleanupDone := make(chan struct{})
+		ctx, _, done, err := startStopper.Start(t.Context(), cleanupDone, nil, startFn)
+		require.NoError(t, err)
+		go func() {
+			<-ctx.Done()
+			close(cleanupDone)
+		}()
+
+		// SUT
+		nextCleanupDone := make(chan struct{})
+		nextCtx, _, nextDone, err := startStopper.RestartStart(t.Context(), nextCleanupDone, nil)
+		require.NoError(t, err)
+		go func() {
+			<-nextCtx.Done()
+			close(nextCleanupDone)
+		}()
+
+		<-done
+		assert.ErrorIs(t, context.Cause(ctx), startstopper.ErrRestarted)
+		assert.Equal(t, startstopper.StateRunning, startStopper.State())
+		assert.Equal(t, int32(2), starts.Load())
+
+		startStopper.Close()
+		<-nextDone
+		assert.ErrorIs(t, startStopper.Cause(), startstopper.ErrClosed)
+	})
+}
+
+func TestStartStopper_Reload(t *testing.T) {
+	var config atomic.Value
+	configure := func(value string, err error) func() error {
+		return func() error {
+			if err != nil {
+				return err
+			}
+			config.Store(value)
+			return nil
+		}
+	}
+
+	t.Run("applies new startFn", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		_, _, _, err := startStopper.StartWorkers(t.Context(), nil, configure("v1", nil), waitGraceful)
+		require.NoError(t, err)
+
+		// SUT
+		_, _, _, err = startStopper.Reload(t.Context(), configure("v2", nil))
+		require.NoError(t, err)
+		assert.Equal(t, "v2", config.Load())
+
+		// known good is kept by Restart
+		config.Store("")
+		_, _, done, err := startStopper.Restart(t.Context(), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "v2", config.Load())
+
+		startStopper.Close()
+		<-done
+	})
+
+	t.Run("rolls back", func(t *testing.T) {
+		errConfig := errors.New("bad config")
+		startStopper := startstopper.New(t.Context(), nil)
+
+		_, _, _, err := startStopper.StartWorkers(t.Context(), nil, configure("v1", nil), waitGraceful)
+		require.NoError(t, err)
+
+		// SUT
+		ctx, _, done, err := startStopper.Reload(t.Context(), configure("v2", errConfig))
+		require.ErrorIs(t, err, startstopper.ErrReload)
+		require.ErrorIs(t, err, errConfig)
+		assert.Equal(t, "v1", config.Load())
+		assert.NoError(t, ctx.Err())
+		assert.Equal(t, startstopper.StateRunning, startStopper.State())
+
+		startStopper.Close()
+		<-done
+	})
+
+	t.Run("rollback fails", func(t *testing.T) {
+		errConfig := errors.New("bad config")
+		errRollback := errors.New("dependency is gone")
+		var fail atomic.Bool
+
+		startStopper := startstopper.New(t.Context(), nil)
+		startFn := func() error {
+			if fail.Load() {
+				return errRollback
+			}
+			return nil
+		}
+
+		_, _, _, err := startStopper.StartWorkers(t.Context(), nil, startFn, waitGraceful)
+		require.NoError(t, err)
+		fail.Store(true)
+
+		// SUT
+		_, _, _, err = startStopper.Reload(t.Context(), configure("v2", errConfig))
+		require.ErrorIs(t, err, startstopper.ErrReload)
+		require.ErrorIs(t, err, errConfig)
+		require.ErrorIs(t, err, errRollback)
+		assert.Equal(t, startstopper.StateStopped, startStopper.State())
+	})
+	t.Run("rolls back StartErr run", func(t *testing.T) {
+		errConfig := errors.New("bad config")
+		errCleanup := errors.New("flush failed")
+		startStopper := startstopper.New(t.Context(), nil)
+
+		cleanupErr, doneFn := startstopper.ErrCloser(nil)
+		ctx, _, done, err := startStopper.StartErr(t.Context(), cleanupErr, nil, configure("v1", nil))
+		require.NoError(t, err)
+		go func() {
+			<-ctx.Done()
+			doneFn(nil)
+		}()
+
+		// SUT
+		nextCleanupErr, nextDoneFn := startstopper.ErrCloser(nil)
+		nextCtx, _, nextDone, err := startStopper.ReloadStartErr(t.Context(), nextCleanupErr, configure("v2", errConfig))
+		require.ErrorIs(t, err, startstopper.ErrReload)
+		require.ErrorIs(t, err, errConfig)
+		assert.Equal(t, "v1", config.Load())
+		<-done
+
+		// rolled back run reports to nextCleanupErr
+		startStopper.CloseAsync()
+		<-nextCtx.Done()
+		nextDoneFn(errCleanup)
+		<-nextDone
+		assert.ErrorIs(t, startStopper.Err(), errCleanup)
+	})
+}
@@ -0,0 +1,200 @@
+package startstopper
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RestartMode defines when Run restarts the service.
+type RestartMode int
+
+const (
+	// RestartNever runs the service once.
+	RestartNever RestartMode = iota
+	// RestartOnFailure restarts if startFn or run returned error.
+	RestartOnFailure
+	// RestartAlways restarts until closed.
+	RestartAlways
+)
+
+var (
+	// RestartInitialBackoffDefault ...
+	RestartInitialBackoffDefault = 100 * time.Millisecond
+	// RestartMaxBackoffDefault ...
+	RestartMaxBackoffDefault = 30 * time.Second
+	// RestartMultiplierDefault ...
+	RestartMultiplierDefault = 2.0
+)
+
+// RestartPolicy ...
+// The zero value never restarts.
+type RestartPolicy struct {
+	Mode           RestartMode
+	MaxAttempts    int           // zero means unlimited
+	InitialBackoff time.Duration // zero means RestartInitialBackoffDefault
+	MaxBackoff     time.Duration // caps delay, jitter included, zero means RestartMaxBackoffDefault
+	Multiplier     float64       // zero means RestartMultiplierDefault
+	Jitter         float64       // randomizes delay by ±Jitter fraction, [0, 1]
+}
+
+// Delay returns backoff before the next attempt after attempt number attempt (starting with 1).
+func (policy RestartPolicy) Delay(attempt int) time.Duration {
+	initial := policy.InitialBackoff
+	if initial <= 0 {
+		initial = RestartInitialBackoffDefault
+	}
+
+	maxBackoff := policy.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = RestartMaxBackoffDefault
+	}
+
+	multiplier := policy.Multiplier
+	if multiplier <= 0 {
+		multiplier = RestartMultiplierDefault
+	}
+
+	delay := math.Min(float64(initial)*math.Pow(multiplier, float64(attempt-1)), float64(maxBackoff))
+
+	// jitter the capped delay and cap again, saturated retries are spread below the cap
+	if policy.Jitter > 0 {
+		delay += delay * policy.Jitter * (2*rand.Float64() - 1)
+		delay = math.Min(delay, float64(maxBackoff))
+	}
+
+	return time.Duration(delay)
+}
+
+func (policy RestartPolicy) restart(attempt int, err error) bool {
+	if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts {
+		return false
+	}
+
+	switch policy.Mode {
+	case RestartAlways:
+		return true
+
+	case RestartOnFailure:
+		return err != nil
+
+	default:
+		return false
+	}
+}
+
+// SetRestartPolicy to be used by Run.
+func (startStopper *StartStopper) SetRestartPolicy(policy RestartPolicy) {
+	WithMutex(&startStopper.mu, func() {
+		startStopper.restartPolicy = policy
+	})
+}
+
+// Run starts the service and restarts it according to restart policy until closed.
+// Each attempt calls startFn (optional) and then run, which must block until attempt is done.
+// Attempt ended without Close or Kill is restarted with backoff.
+// Panic in run is recovered and taken as attempt failure, see PanicError.
+// Close or Kill while waiting between attempts stops Run.
+// readyCh is notified after first successful startFn, or with the error when Run gives up.
+// Blocks until done. Returns error of the last attempt.
+func (startStopper *StartStopper) Run(
+	ctx context.Context,
+	readyCh chan<- error, // optional
+	startFn func() error, // optional
+	run Worker,
+) error {
+	cleanupErr, doneFn := ErrCloser(nil)
+
+	ctx, killCtx, done, err := startStopper.StartErr(ctx, cleanupErr, nil, nil)
+	if err != nil {
+		Notify(readyCh, err, NotifyCloseModeAlways)
+		return err
+	}
+
+	// restart loop runs in the caller goroutine, report it as stuck worker instead of cleanup
+	group := WithMutex1(&startStopper.mu, func() *workerGroup {
+		return startStopper.workers
+	})
+	group.setCleanup(cleanupTracked)
+	untrack := group.track(workerName(run))
+
+	err = startStopper.restartLoop(ctx, killCtx, readyCh, startFn, run)
+
+	untrack()
+
+	doneFn(err)
+	<-done
+
+	return err
+}
+
+func (startStopper *StartStopper) restartLoop(
+	ctx context.Context,
+	killCtx context.Context,
+	readyCh chan<- error,
+	startFn func() error,
+	run Worker,
+) error {
+	var (
+		policy  RestartPolicy
+		metrics *runMetrics
+		runLog  runLogger
+		clock   Clock
+	)
+	WithMutex(&startStopper.mu, func() {
+		clock = startStopper.clock
+		policy = startStopper.restartPolicy
+		metrics = startStopper.runMetrics
+		runLog = startStopper.runLogger
+	})
+
+	gen := startStopper.generation()
+
+	giveUp := func(err error) error {
+		if readyCh != nil {
+			if err == nil {
+				err = errStart
+			}
+			Notify(readyCh, err, NotifyCloseModeAlways)
+		}
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		var err error
+
+		if startFn != nil {
+			startStopper.setState(gen, StateStarting, StateRunning)
+			err = startFn()
+		}
+
+		if err == nil {
+			startStopper.setState(gen, StateRunning, StateStarting)
+			Notify(readyCh, nil, NotifyCloseModeAlways)
+			readyCh = nil
+
+			err, _ = callWorker(run, ctx, killCtx)
+		}
+
+		if ctx.Err() != nil || !policy.restart(attempt, err) {
+			return giveUp(err)
+		}
+
+		delay := policy.Delay(attempt)
+		runLog.restarting(attempt+1, delay, err)
+
+		wake := make(chan struct{})
+		timer := clock.AfterFunc(delay, func() { close(wake) })
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return giveUp(err)
+
+		case <-wake:
+		}
+
+		metrics.restart(attempt + 1)
+	}
+}
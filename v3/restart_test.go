@@ -0,0 +1,163 @@
+package startstopper_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errAttempt = errors.New("attempt failed")
+
+func TestRestartPolicy_Delay(t *testing.T) {
+	policy := startstopper.RestartPolicy{
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     50 * time.Millisecond,
+	}
+
+	assert.Equal(t, 10*time.Millisecond, policy.Delay(1))
+	assert.Equal(t, 20*time.Millisecond, policy.Delay(2))
+	assert.Equal(t, 40*time.Millisecond, policy.Delay(3))
+	assert.Equal(t, 50*time.Millisecond, policy.Delay(4))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := policy.Delay(1)
+		assert.GreaterOrEqual(t, delay, 5*time.Millisecond)
+		assert.LessOrEqual(t, delay, 15*time.Millisecond)
+	}
+
+	// saturated delays are jittered below the cap
+	delays := make(map[time.Duration]struct{})
+	for i := 0; i < 100; i++ {
+		delay := policy.Delay(20)
+		assert.GreaterOrEqual(t, delay, 25*time.Millisecond)
+		assert.LessOrEqual(t, delay, 50*time.Millisecond)
+		delays[delay] = struct{}{}
+	}
+	assert.Greater(t, len(delays), 1)
+}
+
+func TestStartStopper_Run(t *testing.T) {
+	fastPolicy := func(mode startstopper.RestartMode, maxAttempts int) startstopper.RestartPolicy {
+		return startstopper.RestartPolicy{
+			Mode:           mode,
+			MaxAttempts:    maxAttempts,
+			InitialBackoff: time.Millisecond,
+		}
+	}
+
+	t.Run("retry failed startFn", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+		startStopper.SetRestartPolicy(fastPolicy(startstopper.RestartOnFailure, 0))
+
+		starts := 0
+		startFn := func() error {
+			starts++
+			if starts < 3 {
+				return errAttempt
+			}
+			return nil
+		}
+		run := func(ctx context.Context, _ context.Context) error {
+			<-ctx.Done()
+			return nil
+		}
+
+		readyCh := make(chan error, 1)
+		errCh := make(chan error, 1)
+
+		// SUT
+		go func() { errCh <- startStopper.Run(t.Context(), readyCh, startFn, run) }()
+
+		require.NoError(t, <-readyCh)
+
+		startStopper.Close()
+		require.NoError(t, <-errCh)
+		assert.Equal(t, 3, starts)
+	})
+
+	t.Run("restart ended run until max attempts", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+		startStopper.SetRestartPolicy(fastPolicy(startstopper.RestartAlways, 3))
+
+		runs := 0
+		run := func(_ context.Context, _ context.Context) error {
+			runs++
+			return errAttempt
+		}
+
+		// SUT
+		err := startStopper.Run(t.Context(), nil, nil, run)
+		require.ErrorIs(t, err, errAttempt)
+		assert.Equal(t, 3, runs)
+	})
+
+	t.Run("never restart", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		runs := 0
+		run := func(_ context.Context, _ context.Context) error {
+			runs++
+			return errAttempt
+		}
+
+		// SUT
+		err := startStopper.Run(t.Context(), nil, nil, run)
+		require.ErrorIs(t, err, errAttempt)
+		assert.Equal(t, 1, runs)
+	})
+
+	t.Run("on failure does not restart clean exit", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+		startStopper.SetRestartPolicy(fastPolicy(startstopper.RestartOnFailure, 0))
+
+		runs := 0
+		run := func(_ context.Context, _ context.Context) error {
+			runs++
+			if runs < 2 {
+				return errAttempt
+			}
+			return nil
+		}
+
+		// SUT
+		err := startStopper.Run(t.Context(), nil, nil, run)
+		require.NoError(t, err)
+		assert.Equal(t, 2, runs)
+	})
+
+	t.Run("close aborts backoff", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+		startStopper.SetRestartPolicy(startstopper.RestartPolicy{
+			Mode:           startstopper.RestartOnFailure,
+			InitialBackoff: time.Hour,
+		})
+
+		readyCh := make(chan error, 1)
+		errCh := make(chan error, 1)
+		startFn := func() error { return errAttempt }
+
+		// SUT
+		go func() {
+			errCh <- startStopper.Run(t.Context(), readyCh, startFn, nil)
+		}()
+
+		assert.Eventually(t, func() bool {
+			select {
+			case <-startStopper.Done():
+				return false
+			default:
+				return true
+			}
+		}, time.Second, time.Millisecond)
+
+		startStopper.Close()
+		require.ErrorIs(t, <-errCh, errAttempt)
+		require.ErrorIs(t, <-readyCh, errAttempt)
+	})
+}
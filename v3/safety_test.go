@@ -0,0 +1,191 @@
+package startstopper_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartStopper_BeforeStart(t *testing.T) {
+	t.Run("contexts are cancelled", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		// SUT
+		ctx := startStopper.Context()
+		killCtx := startStopper.KillContext()
+
+		<-ctx.Done()
+		<-killCtx.Done()
+		assert.ErrorIs(t, context.Cause(ctx), startstopper.ErrNotStarted)
+		assert.ErrorIs(t, context.Cause(killCtx), startstopper.ErrNotStarted)
+	})
+
+	t.Run("close refuses next Start", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		// SUT
+		startStopper.Close()
+		startStopper.CloseAsync()
+
+		readyCh := make(chan error, 1)
+		_, _, _, err := startStopper.StartWorkers(t.Context(), readyCh, nil, waitGraceful)
+		require.ErrorIs(t, err, startstopper.ErrClosed)
+		require.ErrorIs(t, <-readyCh, startstopper.ErrClosed)
+		assert.Equal(t, startstopper.StateIdle, startStopper.State())
+
+		// remembered once
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil, waitGraceful)
+		require.NoError(t, err)
+
+		startStopper.Close()
+		<-done
+	})
+
+	t.Run("kill refuses next Start", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		// SUT
+		startStopper.KillAsync()
+
+		_, _, _, err := startStopper.StartWorkers(t.Context(), nil, nil, waitGraceful)
+		require.ErrorIs(t, err, startstopper.ErrKilled)
+	})
+}
+
+func TestStartStopper_AfterDone(t *testing.T) {
+	startStopper := startstopper.New(t.Context(), nil)
+
+	ctx, killCtx, done, err := startStopper.StartWorkers(t.Context(), nil, nil, waitGraceful)
+	require.NoError(t, err)
+
+	startStopper.Close()
+	<-done
+
+	// SUT
+	for i := 0; i < 3; i++ {
+		startStopper.CloseAsync()
+		startStopper.KillAsync()
+		startStopper.Close()
+		startStopper.Kill()
+	}
+
+	assert.Same(t, ctx, startStopper.Context())
+	assert.Same(t, killCtx, startStopper.KillContext())
+	assert.ErrorIs(t, startStopper.Cause(), startstopper.ErrClosed)
+	assert.Equal(t, startstopper.StateStopped, startStopper.State())
+
+	// not remembered after Done
+	_, _, done, err = startStopper.StartWorkers(t.Context(), nil, nil, waitGraceful)
+	require.NoError(t, err)
+
+	startStopper.Close()
+	<-done
+}
+
+func TestStartStopper_ConcurrentAccess(t *testing.T) {
+	startStopper := startstopper.New(t.Context(), nil)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				_ = startStopper.Context().Err()
+				_ = startStopper.KillContext().Err()
+				_ = startStopper.Cause()
+				<-startStopper.Done()
+
+				if i == 0 {
+					startStopper.CloseAsync()
+				}
+			}
+		}(i)
+	}
+
+	// SUT
+	for i := 0; i < 20; i++ {
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil, waitGraceful)
+		if err != nil {
+			// refused by close before the first Start
+			require.ErrorIs(t, err, startstopper.ErrClosed)
+			continue
+		}
+
+		if i%2 == 0 {
+			startStopper.KillAsync()
+		}
+		startStopper.Close()
+		<-done
+	}
+
+	close(stop)
+	wg.Wait()
+}
+
+func TestStartStopper_StopWhileStarting(t *testing.T) {
+	tests := []struct {
+		name  string
+		stop  func(startStopper *startstopper.StartStopper)
+		cause error
+	}{
+		{name: "close", stop: (*startstopper.StartStopper).CloseAsync, cause: startstopper.ErrClosed},
+		{name: "kill", stop: (*startstopper.StartStopper).KillAsync, cause: startstopper.ErrKilled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			startStopper := startstopper.New(t.Context(), nil)
+
+			starting := make(chan struct{})
+			release := make(chan struct{})
+			startFn := func() error {
+				close(starting)
+				<-release
+				return nil
+			}
+
+			result := make(chan error, 1)
+			go func() {
+				_, _, _, err := startStopper.StartWorkers(t.Context(), nil, startFn, waitGraceful)
+				result <- err
+			}()
+			<-starting
+
+			// SUT: does not wait for startFn
+			stopped := make(chan struct{})
+			go func() {
+				tt.stop(startStopper)
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+			case <-time.After(time.Second):
+				close(release)
+				t.Fatal("blocked by startFn")
+			}
+
+			close(release)
+			require.NoError(t, <-result)
+
+			// applied once the run is set up
+			<-startStopper.Done()
+			assert.ErrorIs(t, startStopper.Cause(), tt.cause)
+			assert.Equal(t, startstopper.StateStopped, startStopper.State())
+		})
+	}
+}
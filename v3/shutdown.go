@@ -0,0 +1,148 @@
+package startstopper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrLeaked = errors.New("workers leaked")
+	errLeaked = NewErrorCode(ErrLeaked, "STARTSTOPPER_ERR_LEAKED")
+)
+
+// ShutdownPhase is a named step of graceful shutdown, e.g. "stop accepting", "drain", "flush".
+type ShutdownPhase struct {
+	Name    string
+	Timeout time.Duration // how long the phase lasts before next one begins
+}
+
+// SetShutdownPhases sets ordered phases run once graceful shutdown begins.
+// First phase begins with graceful context, kill begins when the last phase times out.
+// Kill ends all phases at once.
+// Without phases kill begins after killTimeoutProvider.
+// Takes effect on next Start.
+func (startStopper *StartStopper) SetShutdownPhases(phases ...ShutdownPhase) {
+	WithMutex(&startStopper.mu, func() {
+		startStopper.phases = append([]ShutdownPhase(nil), phases...)
+	})
+}
+
+// SetAbandonTimeout sets how long to wait for workers after kill begins.
+// When it expires the run is done although workers have not returned,
+// Err reports ErrLeaked. Zero means wait forever.
+// Takes effect on next Start.
+func (startStopper *StartStopper) SetAbandonTimeout(timeout time.Duration) {
+	WithMutex(&startStopper.mu, func() {
+		startStopper.abandonTimeout = timeout
+	})
+}
+
+// PhaseContext returns context of the named shutdown phase of the current (or last) run.
+// It is done when the phase begins. Nil if there is no such phase.
+func (startStopper *StartStopper) PhaseContext(name string) context.Context {
+	return WithMutex1(&startStopper.mu, func() context.Context {
+		for _, phase := range startStopper.phaseRuns {
+			if phase.Name == name {
+				return phase.ctx
+			}
+		}
+		return nil
+	})
+}
+
+// shutdown phase of a single run
+type phaseRun struct {
+	ShutdownPhase
+
+	ctx        context.Context
+	cancelFunc context.CancelCauseFunc
+}
+
+// newPhaseRuns derives phase contexts from killCtx,
+// each phase context is done when any later phase begins.
+func newPhaseRuns(killCtx context.Context, phases []ShutdownPhase) []*phaseRun {
+	runs := make([]*phaseRun, len(phases))
+
+	parent := killCtx
+	for i := len(phases) - 1; i >= 0; i-- {
+		ctx, cancelFunc := context.WithCancelCause(parent)
+		runs[i] = &phaseRun{
+			ShutdownPhase: phases[i],
+			ctx:           ctx,
+			cancelFunc:    cancelFunc,
+		}
+		parent = ctx
+	}
+
+	return runs
+}
+
+// runPhases begins phases one by one, then calls kill.
+func runPhases(clock Clock, timer *killTimer, phases []*phaseRun, cause error, kill func()) {
+	if len(phases) == 0 {
+		kill()
+		return
+	}
+
+	phases[0].cancelFunc(cause)
+	timer.set(clock.AfterFunc(phases[0].Timeout, func() {
+		runPhases(clock, timer, phases[1:], cause, kill)
+	}))
+}
+
+// killTimer of a run, either kill timeout or the current shutdown phase.
+// Stopped once the run is done.
+type killTimer struct {
+	mu      sync.Mutex
+	timer   Timer
+	stopped bool
+}
+
+// set current timer, it is stopped at once if the run is done.
+func (timer *killTimer) set(t Timer) {
+	stop := WithMutex1(&timer.mu, func() bool {
+		timer.timer = t
+		return timer.stopped
+	})
+
+	if stop {
+		t.Stop()
+	}
+}
+
+func (timer *killTimer) stop() {
+	t := WithMutex1(&timer.mu, func() Timer {
+		timer.stopped = true
+		return timer.timer
+	})
+
+	if t != nil {
+		t.Stop()
+	}
+}
+
+// done reports whether the run is done.
+func (timer *killTimer) done() bool {
+	return WithMutex1(&timer.mu, func() bool {
+		return timer.stopped
+	})
+}
+
+// abandon returns joined errors of returned workers and ErrLeaked with names of running workers.
+func (group *workerGroup) abandon() error {
+	return WithMutex1(&group.mu, func() error {
+		names := make([]string, 0, len(group.running))
+		for worker := range group.running {
+			names = append(names, worker.name)
+		}
+		sort.Strings(names)
+
+		leaked := fmt.Errorf("%d still running: %s", group.active, strings.Join(names, ", "))
+		return JoinErrors(append(group.errs, errLeaked, leaked)...)
+	})
+}
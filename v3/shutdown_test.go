@@ -0,0 +1,102 @@
+package startstopper_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartStopper_ShutdownPhases(t *testing.T) {
+	t.Run("phases run in order", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+		startStopper.SetShutdownPhases(
+			startstopper.ShutdownPhase{Name: "stop accepting", Timeout: 10 * time.Millisecond},
+			startstopper.ShutdownPhase{Name: "drain", Timeout: 10 * time.Millisecond},
+		)
+
+		log := &eventLog{}
+
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil,
+			func(ctx context.Context, killCtx context.Context) error {
+				<-ctx.Done()
+				log.add("graceful")
+				<-startStopper.PhaseContext("stop accepting").Done()
+				log.add("stop accepting")
+				<-startStopper.PhaseContext("drain").Done()
+				log.add("drain")
+				<-killCtx.Done()
+				log.add("kill")
+				return nil
+			},
+		)
+		require.NoError(t, err)
+
+		assert.Nil(t, startStopper.PhaseContext("unknown"))
+		require.NoError(t, startStopper.PhaseContext("drain").Err())
+
+		// SUT
+		startStopper.Close()
+		<-done
+
+		assert.Equal(t, []string{"graceful", "stop accepting", "drain", "kill"}, log.events)
+		assert.ErrorIs(t, startStopper.Cause(), startstopper.ErrKillTimeout)
+		assert.ErrorIs(t, context.Cause(startStopper.PhaseContext("drain")), startstopper.ErrClosed)
+	})
+
+	t.Run("kill ends all phases", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+		startStopper.SetShutdownPhases(
+			startstopper.ShutdownPhase{Name: "drain", Timeout: time.Hour},
+			startstopper.ShutdownPhase{Name: "flush", Timeout: time.Hour},
+		)
+
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil,
+			func(_ context.Context, killCtx context.Context) error {
+				<-killCtx.Done()
+				return nil
+			},
+		)
+		require.NoError(t, err)
+
+		startStopper.CloseAsync()
+		<-startStopper.PhaseContext("drain").Done()
+		require.NoError(t, startStopper.PhaseContext("flush").Err())
+
+		// SUT
+		startStopper.Kill()
+		<-done
+
+		require.Error(t, startStopper.PhaseContext("flush").Err())
+		assert.ErrorIs(t, startStopper.Cause(), startstopper.ErrKilled)
+	})
+}
+
+func TestStartStopper_SetAbandonTimeout(t *testing.T) {
+	startStopper := startstopper.New(t.Context(), func(context.Context) time.Duration { return time.Millisecond })
+	startStopper.SetAbandonTimeout(10 * time.Millisecond)
+
+	release := make(chan struct{})
+	defer close(release)
+
+	_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil,
+		waitGraceful,
+		func(context.Context, context.Context) error {
+			<-release
+			return nil
+		},
+	)
+	require.NoError(t, err)
+
+	// SUT
+	err = startStopper.CloseErr()
+	<-done
+
+	require.ErrorIs(t, err, startstopper.ErrLeaked)
+	require.True(t, startstopper.MatchErrorCodes(err, "STARTSTOPPER_ERR_LEAKED"))
+	assert.Regexp(t, `1 still running: \S+TestStartStopper_SetAbandonTimeout\.func\d+$`, err.Error())
+	assert.Equal(t, startstopper.StateStopped, startStopper.State())
+}
@@ -0,0 +1,107 @@
+package startstopper
+
+import (
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// SignalConfig ...
+type SignalConfig struct {
+	Signals     []os.Signal   // nil means SIGINT and SIGTERM
+	GracePeriod time.Duration // KillAsync after first signal, zero means wait for repeated signal
+	Reload      func()        // called on SIGHUP, nil means SIGHUP is not handled
+	Clock       Clock         // runs grace period timer, nil means SystemClock
+}
+
+// HandleSignals calls CloseAsync on first signal and KillAsync on repeated signal or after grace period.
+// Default signal handling is restored once stopper is done or returned func is called,
+// returned func waits for it. Do not call it from Reload.
+// Call after stopper is started.
+func HandleSignals(stopper Stopper, config SignalConfig) func() {
+	signals := config.Signals
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	reload := config.Reload != nil && !slices.ContainsFunc(signals, func(sig os.Signal) bool {
+		return sig == syscall.SIGHUP
+	})
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	if reload {
+		signal.Notify(ch, syscall.SIGHUP)
+	}
+
+	stop := make(chan struct{})
+	exited := make(chan struct{}) // signals are not caught anymore
+	var once sync.Once
+	stopFn := func() {
+		once.Do(func() { close(stop) })
+		<-exited
+	}
+
+	done := stopper.Done()
+
+	clock := config.Clock
+	if clock == nil {
+		clock = SystemClock{}
+	}
+
+	go func() {
+		defer close(exited)
+		defer signal.Stop(ch)
+
+		var (
+			closing bool
+			timer   Timer
+			graceCh chan struct{}
+		)
+
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+
+		for {
+			select {
+			case <-done:
+				return
+
+			case <-stop:
+				return
+
+			case <-graceCh:
+				graceCh = nil
+				stopper.KillAsync()
+
+			case sig := <-ch:
+				if reload && sig == syscall.SIGHUP {
+					config.Reload()
+					continue
+				}
+
+				if closing {
+					stopper.KillAsync()
+					continue
+				}
+
+				closing = true
+				stopper.CloseAsync()
+
+				if config.GracePeriod > 0 {
+					grace := make(chan struct{})
+					timer = clock.AfterFunc(config.GracePeriod, func() { close(grace) })
+					graceCh = grace
+				}
+			}
+		}
+	}()
+
+	return stopFn
+}
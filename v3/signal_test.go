@@ -0,0 +1,113 @@
+//go:build linux
+
+package startstopper_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startKillable starts StartStopper with worker ignoring graceful shutdown.
+func startKillable(t *testing.T) *startstopper.StartStopper {
+	startStopper := startstopper.New(t.Context(), func(context.Context) time.Duration { return time.Hour })
+
+	cleanupDone, doneFn := startstopper.ChanCloser(nil)
+
+	_, killCtx, _, err := startStopper.Start(t.Context(), cleanupDone, nil, nil)
+	require.NoError(t, err)
+
+	go func() {
+		<-killCtx.Done()
+		doneFn()
+	}()
+
+	return startStopper
+}
+
+func sendSignal(t *testing.T, sig syscall.Signal) {
+	require.NoError(t, syscall.Kill(os.Getpid(), sig))
+}
+
+func TestHandleSignals(t *testing.T) {
+	config := startstopper.SignalConfig{Signals: []os.Signal{syscall.SIGUSR1}}
+
+	t.Run("repeated signal kills", func(t *testing.T) {
+		startStopper := startKillable(t)
+
+		// SUT
+		stop := startstopper.HandleSignals(startStopper, config)
+		t.Cleanup(stop)
+
+		sendSignal(t, syscall.SIGUSR1)
+		require.NoError(t, startStopper.WaitFor(t.Context(), startstopper.StateStopping))
+
+		sendSignal(t, syscall.SIGUSR1)
+		<-startStopper.Done()
+	})
+
+	t.Run("grace period kills", func(t *testing.T) {
+		startStopper := startKillable(t)
+
+		config := config
+		config.GracePeriod = time.Millisecond
+
+		// SUT
+		stop := startstopper.HandleSignals(startStopper, config)
+		t.Cleanup(stop)
+
+		sendSignal(t, syscall.SIGUSR1)
+		<-startStopper.Done()
+	})
+
+	t.Run("reload on SIGHUP", func(t *testing.T) {
+		startStopper := startKillable(t)
+		t.Cleanup(startStopper.Kill)
+
+		var reloads atomic.Int32
+		config := config
+		config.Reload = func() { reloads.Add(1) }
+
+		// SUT
+		stop := startstopper.HandleSignals(startStopper, config)
+		t.Cleanup(stop)
+
+		sendSignal(t, syscall.SIGHUP)
+		assert.Eventually(t, func() bool { return reloads.Load() == 1 }, time.Second, time.Millisecond)
+		assert.Equal(t, startstopper.StateRunning, startStopper.State())
+	})
+
+	t.Run("stop restores default handling", func(t *testing.T) {
+		if os.Getenv("STARTSTOPPER_SIGNAL_CHILD") != "" {
+			startStopper := startKillable(t)
+
+			// SUT
+			stop := startstopper.HandleSignals(startStopper, startstopper.SignalConfig{Signals: []os.Signal{syscall.SIGTERM}})
+			stop()
+
+			// terminates the process unless caught
+			sendSignal(t, syscall.SIGTERM)
+			time.Sleep(time.Second)
+			t.Fatal("signal caught after stop")
+		}
+
+		cmd := exec.Command(os.Args[0], "-test.run=^TestHandleSignals$/^stop_restores_default_handling$")
+		cmd.Env = append(os.Environ(), "STARTSTOPPER_SIGNAL_CHILD=1")
+		out, err := cmd.CombinedOutput()
+
+		var exitErr *exec.ExitError
+		require.True(t, errors.As(err, &exitErr), "%v: %s", err, out)
+		status := exitErr.Sys().(syscall.WaitStatus)
+		require.True(t, status.Signaled(), "%s", out)
+		assert.Equal(t, syscall.SIGTERM, status.Signal())
+	})
+}
@@ -0,0 +1,109 @@
+package startstopper
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// RecordedSpan ...
+type RecordedSpan struct {
+	ID       int
+	ParentID int // zero for root spans
+	Name     string
+	Attrs    map[string]slog.Value
+	Errors   []error
+	Start    time.Time
+	End      time.Time // zero while span is not ended
+}
+
+// SpanRecorder is an in-memory Tracer for tests.
+type SpanRecorder struct {
+	mu     sync.Mutex
+	spans  []*RecordedSpan
+	lastID int
+}
+
+type recordedSpanKey struct{}
+
+// NewSpanRecorder ...
+func NewSpanRecorder() *SpanRecorder {
+	return &SpanRecorder{}
+}
+
+// Start ...
+func (recorder *SpanRecorder) Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, Span) {
+	span := &recorderSpan{recorder: recorder}
+
+	WithMutex(&recorder.mu, func() {
+		recorder.lastID++
+		span.span = &RecordedSpan{
+			ID:    recorder.lastID,
+			Name:  name,
+			Attrs: map[string]slog.Value{},
+			Start: time.Now(),
+		}
+		if parent, ok := ctx.Value(recordedSpanKey{}).(*recorderSpan); ok && parent.recorder == recorder {
+			span.span.ParentID = parent.span.ID
+		}
+		for _, attr := range attrs {
+			span.span.Attrs[attr.Key] = attr.Value
+		}
+
+		recorder.spans = append(recorder.spans, span.span)
+	})
+
+	return context.WithValue(ctx, recordedSpanKey{}, span), span
+}
+
+// Spans returns copies of recorded spans in start order.
+func (recorder *SpanRecorder) Spans() []RecordedSpan {
+	return WithMutex1(&recorder.mu, func() []RecordedSpan {
+		spans := make([]RecordedSpan, 0, len(recorder.spans))
+		for _, span := range recorder.spans {
+			s := *span
+			s.Attrs = make(map[string]slog.Value, len(span.Attrs))
+			for key, value := range span.Attrs {
+				s.Attrs[key] = value
+			}
+			s.Errors = append([]error(nil), span.Errors...)
+			spans = append(spans, s)
+		}
+		return spans
+	})
+}
+
+// Reset drops recorded spans.
+func (recorder *SpanRecorder) Reset() {
+	WithMutex(&recorder.mu, func() {
+		recorder.spans = nil
+	})
+}
+
+type recorderSpan struct {
+	recorder *SpanRecorder
+	span     *RecordedSpan // guarded by recorder.mu
+}
+
+func (s *recorderSpan) SetAttributes(attrs ...slog.Attr) {
+	WithMutex(&s.recorder.mu, func() {
+		for _, attr := range attrs {
+			s.span.Attrs[attr.Key] = attr.Value
+		}
+	})
+}
+
+func (s *recorderSpan) RecordError(err error) {
+	WithMutex(&s.recorder.mu, func() {
+		s.span.Errors = append(s.span.Errors, err)
+	})
+}
+
+func (s *recorderSpan) End() {
+	WithMutex(&s.recorder.mu, func() {
+		if s.span.End.IsZero() {
+			s.span.End = time.Now()
+		}
+	})
+}
@@ -3,6 +3,7 @@ package startstopper
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"sync"
 	"time"
 )
@@ -25,6 +26,13 @@ func makeClosedChan[T any]() chan T {
 
 var alwaysClosedChan = makeClosedChan[struct{}]()
 
+// context of StartStopper which has never been started
+var notStartedCtx = func() context.Context {
+	ctx, cancelFunc := context.WithCancelCause(context.Background())
+	cancelFunc(errNotStarted)
+	return ctx
+}()
+
 // StartStopper ...
 // The zero value for StartStopper is ready to use (remember to call Init).
 type StartStopper struct {
@@ -34,12 +42,54 @@ type StartStopper struct {
 	mu   sync.Mutex
 	done chan struct{} // closes when shutdown completes
 
-	gracefulCtx           context.Context    // listen to begin graceful shutdown
-	gracefulCtxCancelFunc context.CancelFunc // cancels gracefulCtx
+	gracefulCtx context.Context // listen to begin graceful shutdown
 
 	killTimeoutProvider func(ctx context.Context) time.Duration
-	killCtx             context.Context    // listen to begin termination
-	killCtxCancelFunc   context.CancelFunc // cancels killCtx
+	killCtx             context.Context // listen to begin termination
+
+	cause error // why the last run stopped
+	err   error // errors of the last run
+
+	pause *pauseState // of the current (or last) run
+
+	runSpec *runSpec // of the last successful run, nil if never started
+
+	restartPolicy RestartPolicy // used by Run
+	failFast      bool          // first worker error closes the run
+	repanic       bool          // panic again after shutdown caused by recovered worker panic
+	workers       *workerGroup  // workers of the current (or last) run
+
+	phases         []ShutdownPhase // run between graceful and kill
+	phaseRuns      []*phaseRun     // phases of the current (or last) run
+	abandonTimeout time.Duration   // after kill, zero means wait forever
+
+	stuckTimeout time.Duration // after kill, zero disables watchdog
+	stuckSink    StuckSink
+
+	name       string       // metrics and logger label
+	metrics    Metrics      // optional
+	runMetrics *runMetrics  // of the current (or last) run
+	logger     *slog.Logger // optional
+	runLogger  runLogger    // of the current (or last) run
+	tracer     Tracer       // optional
+
+	clock Clock // timers
+
+	// Close and Kill do not wait for mu, which is held during startFn
+	cancelMu              sync.Mutex              // guards fields below, may be locked while mu is held
+	gracefulCtxCancelFunc context.CancelCauseFunc // cancels gracefulCtx
+	killCtxCancelFunc     context.CancelCauseFunc // cancels killCtx
+	pendingCause          error                   // Close or Kill before the first Start, refuses the next Start
+	restarting            bool                    // Restart holds the lifecycle
+	starting              bool                    // startFn is running
+	startingStop          stopRequest             // Close and Kill while starting, applied once the run is set up
+
+	stateMu     sync.Mutex // guards fields below, may be locked while mu is held
+	state       State
+	gen         uint64 // run generation, increments on each Start
+	runningGen  uint64 // last run generation which reached StateRunning
+	stateClock  Clock  // stamps transitions, same as clock
+	subscribers map[*subscriber]struct{}
 }
 
 // New ...
@@ -53,17 +103,39 @@ func New(
 }
 
 // must be called once via Init
-func (startStopper *StartStopper) init(
-	killTimeoutProvider func(ctx context.Context) time.Duration,
-) {
+func (startStopper *StartStopper) init(config Config) {
 	startStopper.done = alwaysClosedChan
 
+	killTimeoutProvider := config.KillTimeoutProvider
 	if killTimeoutProvider == nil {
-		killTimeoutProvider = func(_ context.Context) time.Duration { return KillTimeoutDefault }
+		killTimeoutProvider = func(_ context.Context) time.Duration {
+			if config.KillTimeout > 0 {
+				return config.KillTimeout
+			}
+			return KillTimeoutDefault
+		}
 	}
 	startStopper.killTimeoutProvider = killTimeoutProvider
 
-	// startStopper.initErr = nil
+	startStopper.clock = config.Clock
+	if startStopper.clock == nil {
+		startStopper.clock = SystemClock{}
+	}
+	startStopper.stateClock = startStopper.clock
+
+	startStopper.name = config.Name
+	startStopper.logger = config.Logger
+	startStopper.metrics = config.Metrics
+	startStopper.tracer = config.Tracer
+	startStopper.restartPolicy = config.RestartPolicy
+	startStopper.failFast = config.FailFast
+	startStopper.repanic = config.Repanic
+	startStopper.phases = append([]ShutdownPhase(nil), config.ShutdownPhases...)
+	startStopper.abandonTimeout = config.AbandonTimeout
+	startStopper.stuckTimeout = config.StuckTimeout
+	startStopper.stuckSink = config.StuckSink
+
+	startStopper.initErr = config.validate()
 }
 
 // Init to be called from ctor.
@@ -72,7 +144,7 @@ func (startStopper *StartStopper) Init(
 	killTimeoutProvider func(ctx context.Context) time.Duration,
 ) error {
 	startStopper.initOnce.Do(func() {
-		startStopper.init(killTimeoutProvider)
+		startStopper.init(Config{KillTimeoutProvider: killTimeoutProvider})
 	})
 	return startStopper.initErr
 }
@@ -102,58 +174,305 @@ func (startStopper *StartStopper) Start(
 	context.Context, // killCtx context
 	<-chan struct{}, // done chan
 	error,
+) {
+	return startStopper.start(ctx, waitCleanup(cleanupDoneChan), nil, readyCh, startFn, runCleanup, false)
+}
+
+// StartErr like Start, but workers report errors to cleanupErrChan.
+// Run is done when cleanupErrChan is closed, received errors are joined and reported by Err.
+func (startStopper *StartStopper) StartErr(
+	ctx context.Context,
+	cleanupErrChan <-chan error,
+	readyCh chan<- error, // optional
+	startFn func() error, // optional
+) (
+	context.Context, // graceful context
+	context.Context, // killCtx context
+	<-chan struct{}, // done chan
+	error,
+) {
+	return startStopper.start(ctx, waitCleanupErr(cleanupErrChan), nil, readyCh, startFn, runCleanupErr, false)
+}
+
+func waitCleanup(cleanupDoneChan <-chan struct{}) func() error {
+	return func() error {
+		<-cleanupDoneChan
+		return nil
+	}
+}
+
+func waitCleanupErr(cleanupErrChan <-chan error) func() error {
+	return func() error {
+		var errs []error
+		for err := range cleanupErrChan {
+			errs = append(errs, err)
+		}
+		return JoinErrors(errs...)
+	}
+}
+
+// start run, done closes when wait (optional) and all workers returned.
+// kind is remembered for Restart, restart is set by Restart, which holds the lifecycle.
+func (startStopper *StartStopper) start(
+	ctx context.Context,
+	wait func() error,
+	workers []Worker,
+	readyCh chan<- error,
+	startFn func() error,
+	kind runKind,
+	restart bool,
+) (
+	context.Context,
+	context.Context,
+	<-chan struct{},
+	error,
 ) {
 	var (
 		err                   error
+		gen                   uint64
 		done                  chan struct{}
 		gracefulCtx           context.Context
-		gracefulCtxCancelFunc context.CancelFunc
+		gracefulCtxCancelFunc context.CancelCauseFunc
 		killCtx               context.Context
-		killCtxCancelFunc     context.CancelFunc
+		killCtxCancelFunc     context.CancelCauseFunc
+		group                 *workerGroup
+		repanicOnDone         bool
+		abandoned             chan struct{} // closes when abandon timeout expires
+		timer                 *killTimer    // kill timeout or current shutdown phase
+		stopDrain             func() bool   // stops graceful shutdown hook
+		drained               chan struct{} // closes when graceful shutdown hook returned
+		run                   *runMetrics   // nil if run is not started
+		runLog                runLogger
+		runTrace              *runTracer
+		clock                 Clock
+		startedAt             time.Time
 	)
 
 	WithMutex(&startStopper.mu, func() {
+		if startStopper.initErr != nil {
+			err = startStopper.initErr
+			return
+		}
+
 		if startStopper.done != alwaysClosedChan {
 			err = errStart
 			return
 		}
 
+		WithMutex(&startStopper.cancelMu, func() {
+			switch {
+			case startStopper.restarting && !restart:
+				err = errStart
+
+			case startStopper.pendingCause != nil:
+				err = startStopper.pendingCause
+				if restart {
+					// closed during Restart, cleared by Restart
+					startStopper.cause = err
+				} else {
+					startStopper.pendingCause = nil
+				}
+
+			default:
+				startStopper.starting = true
+				startStopper.startingStop = stopRequest{}
+			}
+		})
+		if err != nil {
+			return
+		}
+
+		clock = startStopper.clock
+		startedAt = clock.Now()
+
+		gen = startStopper.beginRun()
+		run = &runMetrics{name: startStopper.name, metrics: startStopper.metrics, clock: clock}
+		runLog = newRunLogger(startStopper.name, startStopper.logger)
+		runLog.starting()
+		runTrace = newRunTracer(ctx, startStopper.tracer, startStopper.name)
+		ctx = runTrace.ctx
+
 		if startFn != nil {
-			err = startFn()
+			err = runTrace.startFn(startFn)
 			if err != nil {
+				// nothing to stop
+				WithMutex(&startStopper.cancelMu, func() {
+					startStopper.starting = false
+				})
+				startStopper.setState(gen, StateStopped)
 				return
 			}
 		}
 
+		if runLog.logger != nil {
+			ctx = ContextWithLogger(ctx, runLog.logger)
+		}
+
 		done = make(chan struct{})
-		gracefulCtx, gracefulCtxCancelFunc = context.WithCancel(ctx)
-		killCtx, killCtxCancelFunc = context.WithCancel(context.WithoutCancel(gracefulCtx))
+		gracefulCtx, gracefulCtxCancelFunc = context.WithCancelCause(ctx)
+		killCtx, killCtxCancelFunc = context.WithCancelCause(context.WithoutCancel(gracefulCtx))
+
+		timer = &killTimer{}
+		phases := newPhaseRuns(killCtx, startStopper.phases)
+		abandonTimeout := startStopper.abandonTimeout
+		abandoned = make(chan struct{})
+
+		// cancel killCtx with timeout, or after shutdown phases.
+		// Not run when cleanup is done first, see stopDrain.
+		drained = make(chan struct{})
+		stopDrain = context.AfterFunc(gracefulCtx, func() {
+			defer close(drained)
+
+			startStopper.setState(gen, StateStopping, StateStarting, StateRunning, StatePaused)
+			run.stopping()
+
+			cause := context.Cause(gracefulCtx)
+			runLog.stopping(cause)
+
+			var timeout time.Duration
+			if len(phases) > 0 {
+				for _, phase := range phases {
+					timeout += phase.Timeout
+				}
+			} else {
+				timeout = startStopper.killTimeoutProvider(killCtx)
+			}
+			runTrace.enter(SpanDrain, causeAttr(cause), killTimeoutAttr(timeout))
+
+			kill := func() {
+				if timer.done() {
+					// fired while the run was finishing
+					return
+				}
+				if killCtx.Err() == nil {
+					runLog.killTimeout(timeout)
+				}
+				killCtxCancelFunc(JoinErrors(errKillTimeout, cause))
+			}
+
+			if len(phases) > 0 {
+				runPhases(clock, timer, phases, cause, kill)
+				return
+			}
+			timer.set(clock.AfterFunc(timeout, kill))
+		})
 
-		// cancel killCtx with timeout
-		context.AfterFunc(gracefulCtx, func() {
-			time.AfterFunc(startStopper.killTimeoutProvider(killCtx), killCtxCancelFunc)
+		context.AfterFunc(killCtx, func() {
+			startStopper.setState(gen, StateKilling, StateStarting, StateRunning, StatePaused, StateStopping)
+			runTrace.enter(SpanKill, causeAttr(context.Cause(killCtx)))
+			if abandonTimeout > 0 {
+				clock.AfterFunc(abandonTimeout, func() { close(abandoned) })
+			}
 		})
 
 		startStopper.gracefulCtx = gracefulCtx
-		startStopper.gracefulCtxCancelFunc = gracefulCtxCancelFunc
 		startStopper.killCtx = killCtx
-		startStopper.killCtxCancelFunc = killCtxCancelFunc
+		startStopper.phaseRuns = phases
+		startStopper.runMetrics = run
+		startStopper.runLogger = runLog
 
 		startStopper.done = done
+
+		closeRun := func(err error) {
+			gracefulCtxCancelFunc(err)
+			startStopper.setState(gen, StateStopping, StateStarting, StateRunning, StatePaused)
+		}
+
+		group = newWorkerGroup(gracefulCtx, killCtx)
+		group.onPanic = closeRun
+		if startStopper.failFast {
+			group.onError = closeRun
+		}
+		group.watch(clock, startStopper.stuckTimeout, startStopper.stuckSink)
+		startStopper.workers = group
+		repanicOnDone = startStopper.repanic
+
+		startStopper.pause = newPauseState()
+		startStopper.runSpec = &runSpec{kind: kind, startFn: startFn, workers: workers}
+		startStopper.setState(gen, StateRunning, StateStarting)
+		runTrace.enter(SpanRunning)
+
+		stop := WithMutex1(&startStopper.cancelMu, func() stopRequest {
+			startStopper.gracefulCtxCancelFunc = gracefulCtxCancelFunc
+			startStopper.killCtxCancelFunc = killCtxCancelFunc
+			startStopper.starting = false
+			return startStopper.startingStop
+		})
+		startStopper.applyStop(stop)
 	})
 
+	if run != nil {
+		latency := clock.Now().Sub(startedAt)
+		run.start(latency, err)
+		runLog.started(latency, err)
+		if err != nil {
+			runTrace.end(nil, err)
+		}
+	}
+
 	if err == nil {
+		if wait != nil {
+			group.add()
+			go func() {
+				group.setCleanup(cleanupPending)
+				err := wait()
+				group.setCleanup(cleanupDone)
+
+				group.finish(err, false)
+			}()
+		}
+
+		for _, worker := range workers {
+			group.add()
+			group.launch(worker)
+		}
+
+		// release startup hold
+		group.finish(nil, false)
+
 		// Setup cleanup.
 		go func() {
-			<-cleanupDoneChan
+			var runErr error
+			select {
+			case <-group.done:
+				runErr = group.wait()
+
+			case <-abandoned:
+				runErr = group.abandon()
+			}
+
+			// shutdown hook has returned or never runs, logs stay ordered
+			if !stopDrain() {
+				<-drained
+			}
+			timer.stop()
 
 			// make sure contexts dont leak
-			gracefulCtxCancelFunc()
+			gracefulCtxCancelFunc(errCompleted)
+
+			// AfterFunc hooks may not have run yet, keep transitions ordered
+			startStopper.setState(gen, StateStopping, StateStarting, StateRunning, StatePaused)
+			if killCtx.Err() != nil {
+				startStopper.setState(gen, StateKilling, StateStarting, StateRunning, StatePaused, StateStopping)
+				runTrace.enter(SpanKill, causeAttr(context.Cause(killCtx)))
+			}
+
+			cause := runCause(gracefulCtx, killCtx)
+			run.shutdown(gracefulCtx, killCtx)
+			runLog.stopped(clock.Now().Sub(startedAt), cause, runErr)
+			runTrace.end(cause, runErr)
 
 			WithMutex(&startStopper.mu, func() {
+				startStopper.cause = cause
+				startStopper.err = runErr
+				startStopper.setState(gen, StateStopped)
 				close(done)
 				startStopper.done = alwaysClosedChan
 			})
+
+			if panicErr := group.panicked(); panicErr != nil && repanicOnDone {
+				repanic(panicErr)
+			}
 		}()
 	}
 
@@ -173,16 +492,28 @@ func (startStopper *StartStopper) Done() <-chan struct{} {
 	return ch
 }
 
-// Context returns context for graceful shutdown.
-// @TODO SAFETY ???
+// Context returns context for graceful shutdown of the current (or last) run.
+// Before the first Start it is cancelled with ErrNotStarted.
+// Threadsafe.
 func (startStopper *StartStopper) Context() context.Context {
-	return startStopper.gracefulCtx
+	return WithMutex1(&startStopper.mu, func() context.Context {
+		if startStopper.gracefulCtx == nil {
+			return notStartedCtx
+		}
+		return startStopper.gracefulCtx
+	})
 }
 
-// KillContext returns context for kill.
-// @TODO SAFETY ???
+// KillContext returns context for kill of the current (or last) run.
+// Before the first Start it is cancelled with ErrNotStarted.
+// Threadsafe.
 func (startStopper *StartStopper) KillContext() context.Context {
-	return startStopper.killCtx
+	return WithMutex1(&startStopper.mu, func() context.Context {
+		if startStopper.killCtx == nil {
+			return notStartedCtx
+		}
+		return startStopper.killCtx
+	})
 }
 
 // Close tries to stop the loop gracefully. Kill after timeout.
@@ -205,15 +536,40 @@ func (startStopper *StartStopper) Kill() {
 	<-done
 }
 
+// Err returns joined errors of workers and cleanup of the last run.
+// Valid after Done is closed, nil while running.
+// Error codes are preserved, see MatchErrorCodes.
+func (startStopper *StartStopper) Err() error {
+	return WithMutex1(&startStopper.mu, func() error {
+		if startStopper.done != alwaysClosedChan {
+			return nil
+		}
+		return startStopper.err
+	})
+}
+
+// CloseErr like Close, returns Err.
+func (startStopper *StartStopper) CloseErr() error {
+	startStopper.Close()
+	return startStopper.Err()
+}
+
+// KillErr like Kill, returns Err.
+func (startStopper *StartStopper) KillErr() error {
+	startStopper.Kill()
+	return startStopper.Err()
+}
+
 // CloseAsync like Close but dont wait.
-// @TODO SAFETY ???
+// Before the first Start it makes the next Start fail with ErrClosed.
+// No-op after Done. Threadsafe.
 func (startStopper *StartStopper) CloseAsync() {
-	startStopper.gracefulCtxCancelFunc()
+	startStopper.closeAsync(errClosed)
 }
 
 // KillAsync like Kill but dont wait.
-// @TODO SAFETY ???
+// Before the first Start it makes the next Start fail with ErrKilled.
+// No-op after Done. Threadsafe.
 func (startStopper *StartStopper) KillAsync() {
-	startStopper.gracefulCtxCancelFunc()
-	startStopper.killCtxCancelFunc()
+	startStopper.killAsync(errKilled)
 }
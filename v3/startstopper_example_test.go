@@ -2,6 +2,7 @@ package startstopper_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -119,62 +120,16 @@ func (srv *Srv) loop(
 	doneFn func(),
 ) {
 	defer doneFn() // signal startstopper this loop is done
-
-	ctxDone := ctx.Done()
-	killChan := killCtx.Done()
-	counter := 1
-
-	var (
-		dieNow     bool
-		inShutdown bool
-		inChan     chan int
-	)
-
 	defer srv.cleanupLoop(ctx)
 
-	for {
-		if dieNow {
-			// single return
-			return
-		}
-
-		inChan = srv.C
-		if inShutdown && counter == 0 {
-			// e.g., stop reading from chan
-			inChan = nil
-		}
-
-		// prioritize kill (optional implementation)
-		select {
-		case <-killChan:
-			killChan = nil
-			dieNow = true
-			continue
-		default:
-		}
-
-		select {
-		case <-killChan:
-			killChan = nil // we do not want more signals
-			dieNow = true
-			continue
-
-		case <-ctxDone:
-			ctxDone = nil // we do not want more signals for shutdown
-			inShutdown = true
-
-		//	business cases
-		case v := <-inChan:
-			_ = srv.process(v)
-
-			if inShutdown {
-				counter--
-				if counter == 0 {
-					dieNow = true
-				}
-			}
-		}
+	handle := func(_ context.Context, msg int) error {
+		return srv.process(msg)
 	}
+
+	// kill takes precedence, on graceful shutdown one more message is processed
+	_ = startstopper.Loop(srv.C, handle, startstopper.LoopSpec{
+		Drain: startstopper.DrainPolicy{Mode: startstopper.DrainItems, Items: 1},
+	})(ctx, killCtx)
 }
 
 func (srv *Srv) Close() error {
@@ -224,3 +179,81 @@ func ExampleStartStopper() {
 	// <nil>
 	// done
 }
+
+func ExampleStartStopper_StartWorkers() {
+	ctx := context.Background()
+
+	startStopper := startstopper.New(ctx, nil)
+	in := make(chan int)
+
+	consume := func(ctx context.Context, _ context.Context) error {
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case v := <-in:
+				fmt.Println(v)
+			}
+		}
+	}
+
+	// no need to count workers, done closes when the last one returns
+	_, _, done, err := startStopper.StartWorkers(ctx, nil, nil, consume)
+	if err != nil {
+		fmt.Println("StartWorkers returned error:", err)
+		return
+	}
+
+	in <- 1
+	in <- 2
+
+	_ = startStopper.Go(func(ctx context.Context, _ context.Context) error {
+		<-ctx.Done()
+		return errors.New("flush failed")
+	})
+
+	fmt.Println(startStopper.CloseErr())
+
+	<-done
+	fmt.Println("done")
+
+	// Output:
+	// 1
+	// 2
+	// flush failed
+	// done
+}
+
+func ExampleLoop() {
+	ctx := context.Background()
+
+	startStopper := startstopper.New(ctx, nil)
+	in := make(chan int, 3)
+
+	print := func(_ context.Context, v int) error {
+		fmt.Println(v)
+		return nil
+	}
+
+	// the zero spec drains input until it is closed
+	_, _, done, err := startStopper.StartWorkers(ctx, nil, nil, startstopper.Loop(in, print, startstopper.LoopSpec{}))
+	if err != nil {
+		fmt.Println("StartWorkers returned error:", err)
+		return
+	}
+
+	in <- 1
+	in <- 2
+	startStopper.CloseAsync()
+	in <- 3
+	close(in)
+
+	<-done
+	fmt.Println("done")
+
+	// Output:
+	// 1
+	// 2
+	// 3
+	// done
+}
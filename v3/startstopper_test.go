@@ -1,6 +1,8 @@
 package startstopper_test
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -109,3 +111,72 @@ func TestStartStopper_StartStopStartStop(t *testing.T) {
 		}
 	})
 }
+
+func TestStartStopper_Err(t *testing.T) {
+	errWorker := startstopper.NewErrorCode(errors.New("worker failed"), "WORKER_FAILED")
+
+	t.Run("worker errors", func(t *testing.T) {
+		startStopper := startstopper.StartStopper{}
+		err := startStopper.Init(t.Context(), nil)
+		require.NoError(t, err)
+
+		cleanupErr, doneFn := startstopper.ErrCloserWaitGroup(nil, 2)
+
+		// SUT
+		ctx, _, done, err := startStopper.StartErr(t.Context(), cleanupErr, nil, nil)
+		require.NoError(t, err)
+
+		go func() {
+			<-ctx.Done()
+			doneFn(nil)
+		}()
+		go func() {
+			<-ctx.Done()
+			doneFn(errWorker)
+		}()
+
+		assert.NoError(t, startStopper.Err())
+
+		err = startStopper.CloseErr()
+		<-done
+
+		require.ErrorIs(t, err, errWorker)
+		require.ErrorIs(t, startStopper.Err(), errWorker)
+		require.True(t, startstopper.MatchErrorCodes(err, "WORKER_FAILED"))
+	})
+
+	t.Run("no errors", func(t *testing.T) {
+		startStopper := startstopper.StartStopper{}
+		err := startStopper.Init(t.Context(), nil)
+		require.NoError(t, err)
+
+		cleanupErr, doneFn := startstopper.ErrCloser(nil)
+
+		// SUT
+		_, killCtx, _, err := startStopper.StartErr(t.Context(), cleanupErr, nil, nil)
+		require.NoError(t, err)
+
+		go func() {
+			<-killCtx.Done()
+			doneFn(nil)
+		}()
+
+		require.NoError(t, startStopper.KillErr())
+	})
+}
+
+func TestJoinErrors(t *testing.T) {
+	errA := startstopper.NewErrorCode(errors.New("a"), "CODE_A")
+	errB := startstopper.NewErrorCode(errors.New("b"), "CODE_B")
+
+	require.NoError(t, startstopper.JoinErrors(nil, nil))
+
+	// SUT
+	err := startstopper.JoinErrors(errA, nil, fmt.Errorf("wrapped: %w", startstopper.JoinErrors(errB)))
+	require.ErrorIs(t, err, errA)
+	require.ErrorIs(t, err, errB)
+	assert.Equal(t, "a\nwrapped: b", err.Error())
+	assert.True(t, startstopper.MatchErrorCodes(err, "CODE_A"))
+	assert.True(t, startstopper.MatchErrorCodes(err, "CODE_B"))
+	assert.False(t, startstopper.MatchErrorCodes(err, "CODE_C"))
+}
@@ -0,0 +1,143 @@
+package startstoppertest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+)
+
+// FakeClock is a startstopper.Clock for deterministic tests.
+// Time moves only with Advance, which fires due timers synchronously.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []*fakeTimer
+	changed chan struct{} // closes when timers change
+}
+
+type fakeTimer struct {
+	clock    *FakeClock
+	deadline time.Time
+	f        func()
+}
+
+// NewFakeClock starting at now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{
+		now:     now,
+		changed: make(chan struct{}),
+	}
+}
+
+// Now ...
+func (clock *FakeClock) Now() time.Time {
+	return startstopper.WithMutex1(&clock.mu, func() time.Time {
+		return clock.now
+	})
+}
+
+// AfterFunc ...
+func (clock *FakeClock) AfterFunc(d time.Duration, f func()) startstopper.Timer {
+	timer := &fakeTimer{clock: clock, f: f}
+
+	startstopper.WithMutex(&clock.mu, func() {
+		timer.deadline = clock.now.Add(d)
+		clock.timers = append(clock.timers, timer)
+		clock.notify()
+	})
+
+	return timer
+}
+
+// Advance moves time by d and fires due timers in deadline order, in the calling goroutine.
+// Timers set by fired timers fire too if due before the new time.
+// Fired timers may call Advance, time never moves backwards.
+func (clock *FakeClock) Advance(d time.Duration) {
+	target := clock.Now().Add(d)
+
+	for {
+		timer := startstopper.WithMutex1(&clock.mu, func() *fakeTimer {
+			i := -1
+			for j, timer := range clock.timers {
+				if !timer.deadline.After(target) && (i < 0 || timer.deadline.Before(clock.timers[i].deadline)) {
+					i = j
+				}
+			}
+
+			if i < 0 {
+				// fired timers may have advanced the clock further
+				if target.After(clock.now) {
+					clock.now = target
+				}
+				return nil
+			}
+
+			timer := clock.timers[i]
+			clock.timers = append(clock.timers[:i], clock.timers[i+1:]...)
+			clock.now = timer.deadline
+			clock.notify()
+			return timer
+		})
+
+		if timer == nil {
+			return
+		}
+
+		timer.f()
+	}
+}
+
+// Pending returns number of timers not yet fired or stopped.
+func (clock *FakeClock) Pending() int {
+	return startstopper.WithMutex1(&clock.mu, func() int {
+		return len(clock.timers)
+	})
+}
+
+// BlockUntil waits until at least n timers are pending.
+// StartStopper sets timers asynchronously (e.g. kill timer after CloseAsync),
+// wait for them before Advance.
+func (clock *FakeClock) BlockUntil(ctx context.Context, n int) error {
+	for {
+		var changed chan struct{}
+		startstopper.WithMutex(&clock.mu, func() {
+			if len(clock.timers) < n {
+				changed = clock.changed
+			}
+		})
+
+		if changed == nil {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// notify BlockUntil, mu must be held.
+func (clock *FakeClock) notify() {
+	close(clock.changed)
+	clock.changed = make(chan struct{})
+}
+
+// Stop ...
+func (timer *fakeTimer) Stop() bool {
+	clock := timer.clock
+
+	return startstopper.WithMutex1(&clock.mu, func() bool {
+		for i, t := range clock.timers {
+			if t == timer {
+				clock.timers = append(clock.timers[:i], clock.timers[i+1:]...)
+				clock.notify()
+				return true
+			}
+		}
+		return false
+	})
+}
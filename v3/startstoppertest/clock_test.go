@@ -0,0 +1,131 @@
+package startstoppertest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/Darigaaz/startstopper/v3/startstoppertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeClock(t *testing.T) {
+	t.Run("kill timer", func(t *testing.T) {
+		clock := startstoppertest.NewFakeClock(time.Unix(0, 0))
+
+		startStopper := startstopper.New(t.Context(), nil)
+		startStopper.SetClock(clock)
+
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil,
+			func(_ context.Context, killCtx context.Context) error {
+				<-killCtx.Done()
+				return nil
+			},
+		)
+		require.NoError(t, err)
+
+		startStopper.CloseAsync()
+		require.NoError(t, clock.BlockUntil(t.Context(), 1))
+
+		// SUT
+		clock.Advance(startstopper.KillTimeoutDefault - time.Nanosecond)
+		require.NoError(t, startStopper.KillContext().Err())
+
+		clock.Advance(time.Nanosecond)
+		<-done
+
+		require.ErrorIs(t, startStopper.Cause(), startstopper.ErrKillTimeout)
+		assert.Zero(t, clock.Pending())
+	})
+
+	t.Run("shutdown phases fire in one Advance", func(t *testing.T) {
+		clock := startstoppertest.NewFakeClock(time.Unix(0, 0))
+
+		startStopper := startstopper.New(t.Context(), nil)
+		startStopper.SetClock(clock)
+		startStopper.SetShutdownPhases(
+			startstopper.ShutdownPhase{Name: "drain", Timeout: time.Minute},
+			startstopper.ShutdownPhase{Name: "flush", Timeout: time.Minute},
+		)
+
+		_, killCtx, done, err := startStopper.StartWorkers(t.Context(), nil, nil,
+			func(_ context.Context, killCtx context.Context) error {
+				<-killCtx.Done()
+				return nil
+			},
+		)
+		require.NoError(t, err)
+
+		startStopper.CloseAsync()
+		require.NoError(t, clock.BlockUntil(t.Context(), 1))
+
+		// SUT
+		clock.Advance(2 * time.Minute)
+
+		require.Error(t, killCtx.Err())
+		<-done
+		assert.Equal(t, time.Unix(0, 0).Add(2*time.Minute), clock.Now())
+	})
+
+	t.Run("restart backoff", func(t *testing.T) {
+		clock := startstoppertest.NewFakeClock(time.Unix(0, 0))
+
+		startStopper := startstopper.New(t.Context(), nil)
+		startStopper.SetClock(clock)
+		startStopper.SetRestartPolicy(startstopper.RestartPolicy{
+			Mode:           startstopper.RestartOnFailure,
+			MaxAttempts:    3,
+			InitialBackoff: time.Hour,
+		})
+
+		errFailed := errors.New("failed")
+		attempts := make(chan struct{}, 3)
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- startStopper.Run(t.Context(), nil, nil, func(context.Context, context.Context) error {
+				attempts <- struct{}{}
+				return errFailed
+			})
+		}()
+
+		for i := 0; i < 2; i++ {
+			<-attempts
+			require.NoError(t, clock.BlockUntil(t.Context(), 1))
+
+			// SUT
+			clock.Advance(time.Duration(i+1) * time.Hour)
+		}
+		<-attempts
+
+		require.ErrorIs(t, <-errCh, errFailed)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		clock := startstoppertest.NewFakeClock(time.Unix(0, 0))
+
+		fired := false
+		timer := clock.AfterFunc(time.Second, func() { fired = true })
+
+		// SUT
+		require.True(t, timer.Stop())
+		require.False(t, timer.Stop())
+
+		clock.Advance(time.Second)
+		assert.False(t, fired)
+	})
+
+	t.Run("nested advance", func(t *testing.T) {
+		clock := startstoppertest.NewFakeClock(time.Unix(0, 0))
+
+		clock.AfterFunc(time.Second, func() { clock.Advance(time.Minute) })
+
+		// SUT
+		clock.Advance(time.Second)
+
+		assert.Equal(t, time.Unix(61, 0), clock.Now())
+	})
+}
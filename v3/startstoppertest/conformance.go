@@ -0,0 +1,116 @@
+package startstoppertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+)
+
+// Factory creates a new service for each conformance scenario.
+type Factory func(ctx context.Context) startstopper.Service
+
+// RunConformance runs lifecycle scenarios as subtests against services created by factory:
+//   - start and close
+//   - start twice: second Start fails with ErrStart while running
+//   - close during start: parent context is cancelled while Start is in progress
+//   - kill while draining
+//   - restart five times on the same instance
+//
+// Each scenario checks that Start returns, Done closes and goroutines started by the run exit.
+func RunConformance(t *testing.T, factory Factory) {
+	t.Run("start and close", func(t *testing.T) {
+		svc := factory(context.Background())
+
+		run := startReady(t, svc)
+
+		svc.CloseAsync()
+		run.wait(t, svc)
+	})
+
+	t.Run("start twice", func(t *testing.T) {
+		svc := factory(context.Background())
+
+		run := startReady(t, svc)
+
+		readyCh := make(chan error, 1)
+		err := svc.Start(context.Background(), readyCh)
+		if !errors.Is(err, startstopper.ErrStart) {
+			t.Errorf("second Start: expected ErrStart, got %v", err)
+		}
+		if err := receive(t, readyCh, "second Start readiness"); !errors.Is(err, startstopper.ErrStart) {
+			t.Errorf("second Start readiness: expected ErrStart, got %v", err)
+		}
+
+		svc.CloseAsync()
+		run.wait(t, svc)
+	})
+
+	t.Run("close during start", func(t *testing.T) {
+		svc := factory(context.Background())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		readyCh := make(chan error, 1)
+
+		run := goStart(ctx, svc, readyCh)
+		cancel()
+
+		// either started and closed right away, or failed to start
+		_ = receive(t, readyCh, "readiness")
+		run.wait(t, svc)
+	})
+
+	t.Run("kill while draining", func(t *testing.T) {
+		svc := factory(context.Background())
+
+		run := startReady(t, svc)
+
+		svc.CloseAsync()
+		svc.KillAsync()
+		run.wait(t, svc)
+	})
+
+	t.Run("restart five times", func(t *testing.T) {
+		svc := factory(context.Background())
+
+		for i := 0; i < 5; i++ {
+			run := startReady(t, svc)
+
+			svc.CloseAsync()
+			run.wait(t, svc)
+
+			if t.Failed() {
+				t.Fatalf("restart %d failed", i+1)
+			}
+		}
+	})
+}
+
+// startReady starts svc and waits until it is ready.
+func startReady(t *testing.T, svc startstopper.Service) *startRun {
+	t.Helper()
+
+	readyCh := make(chan error, 1)
+	run := goStart(context.Background(), svc, readyCh)
+
+	if err := receive(t, readyCh, "readiness"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	return run
+}
+
+func receive(t *testing.T, ch <-chan error, what string) error {
+	t.Helper()
+
+	select {
+	case err := <-ch:
+		return err
+
+	case <-time.After(TimeoutDefault):
+		t.Fatalf("no %s after %s", what, TimeoutDefault)
+		return nil
+	}
+}
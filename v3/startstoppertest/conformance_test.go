@@ -0,0 +1,84 @@
+package startstoppertest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/Darigaaz/startstopper/v3/startstoppertest"
+	"github.com/stretchr/testify/require"
+)
+
+// worker service, like Srv in startstopper example
+type workerService struct {
+	startstopper.StartStopper
+}
+
+func newWorkerService(ctx context.Context) *workerService {
+	svc := &workerService{}
+	_ = svc.StartStopper.Init(ctx, nil)
+	return svc
+}
+
+func (svc *workerService) Start(ctx context.Context, readyCh chan<- error) error {
+	err := svc.StartStopper.InitNotify(ctx, readyCh, nil)
+	if err != nil {
+		return err
+	}
+
+	_, _, done, err := svc.StartStopper.StartWorkers(ctx, readyCh, nil,
+		func(ctx context.Context, _ context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		func(_ context.Context, killCtx context.Context) error {
+			// drains until killed
+			<-killCtx.Done()
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	<-done
+	return nil
+}
+
+func TestRunConformance(t *testing.T) {
+	t.Run("worker service", func(t *testing.T) {
+		startstoppertest.RunConformance(t, func(ctx context.Context) startstopper.Service {
+			return newWorkerService(ctx)
+		})
+	})
+
+	t.Run("supervisor", func(t *testing.T) {
+		startstoppertest.RunConformance(t, func(ctx context.Context) startstopper.Service {
+			return startstopper.NewSupervisor(ctx, startstopper.SupervisorSpec{},
+				startstopper.ChildSpec{Name: "a", Service: newWorkerService(ctx)},
+				startstopper.ChildSpec{Name: "b", Service: newWorkerService(ctx)},
+			)
+		})
+	})
+
+	t.Run("group", func(t *testing.T) {
+		startstoppertest.RunConformance(t, func(ctx context.Context) startstopper.Service {
+			return startstopper.NewGroup(ctx, startstopper.GroupSpec{},
+				startstopper.GroupMember{Name: "db", Service: newWorkerService(ctx)},
+				startstopper.GroupMember{Name: "api", Service: newWorkerService(ctx), DependsOn: []string{"db"}},
+			)
+		})
+	})
+}
+
+func TestStart(t *testing.T) {
+	svc := newWorkerService(t.Context())
+
+	// SUT
+	errCh := startstoppertest.Start(t, svc)
+
+	require.Equal(t, startstopper.StateRunning, svc.State())
+
+	svc.Close()
+	require.NoError(t, <-errCh)
+}
@@ -0,0 +1,90 @@
+package startstoppertest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingTB records errors instead of failing
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (tb *recordingTB) Helper() {}
+
+func (tb *recordingTB) Errorf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func TestCheckLeaks(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	label := make(chan string)
+	go func() {
+		label <- labelRun()
+
+		// creators exit before the check
+		go func() {
+			go func() {
+				<-release
+			}()
+		}()
+	}()
+
+	tb := &recordingTB{TB: t}
+
+	// SUT
+	checkLeaks(tb, <-label, 10*time.Millisecond)
+
+	if assert.Len(t, tb.errors, 1) {
+		assert.Contains(t, tb.errors[0], "1 goroutines survived Done")
+		assert.Contains(t, tb.errors[0], "leak_test.go")
+	}
+
+	// no leaks
+	tb = &recordingTB{TB: t}
+	go func() {
+		label <- labelRun()
+	}()
+	checkLeaks(tb, <-label, 10*time.Millisecond)
+	assert.Empty(t, tb.errors)
+}
+
+func TestCheckLeaks_Parallel(t *testing.T) {
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	label := make(chan string)
+	go func() {
+		label <- labelRun()
+	}()
+	run := <-label
+	started := make(chan struct{})
+
+	// another test, not parallel to avoid deadlock with -parallel 1
+	t.Run("unrelated", func(t *testing.T) {
+		// created after the run by a goroutine which exited
+		go func() {
+			go func() {
+				close(started)
+				<-release
+			}()
+		}()
+	})
+
+	t.Run("check", func(t *testing.T) {
+		t.Parallel()
+		<-started
+
+		tb := &recordingTB{TB: t}
+
+		// SUT
+		checkLeaks(tb, run, 10*time.Millisecond)
+		assert.Empty(t, tb.errors)
+	})
+}
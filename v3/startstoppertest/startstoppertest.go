@@ -0,0 +1,89 @@
+package startstoppertest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+)
+
+var (
+	// TimeoutDefault for readiness, shutdown and leaked goroutines to exit.
+	TimeoutDefault = 5 * time.Second
+)
+
+// Start svc in background and wait until it is ready, test fails on start error.
+// On test cleanup svc is closed, Done and Start return are awaited
+// and goroutines started by the run must exit.
+// Returns channel receiving error returned by Start.
+func Start(t testing.TB, svc startstopper.Service) <-chan error {
+	t.Helper()
+
+	readyCh := make(chan error, 1)
+	run := goStart(context.Background(), svc, readyCh)
+
+	t.Cleanup(func() {
+		svc.CloseAsync()
+		run.wait(t, svc)
+	})
+
+	select {
+	case err := <-readyCh:
+		if err != nil {
+			t.Fatalf("startstoppertest: start: %v", err)
+		}
+
+	case <-time.After(TimeoutDefault):
+		t.Fatalf("startstoppertest: not ready after %s", TimeoutDefault)
+	}
+
+	return run.errCh
+}
+
+// single Start call in its own goroutine
+type startRun struct {
+	label    chan string // of goroutines created by the run, see labelRun
+	returned chan struct{}
+	errCh    chan error // buffered, receives Start result
+}
+
+func goStart(ctx context.Context, svc startstopper.Service, readyCh chan<- error) *startRun {
+	run := &startRun{
+		label:    make(chan string, 1),
+		returned: make(chan struct{}),
+		errCh:    make(chan error, 1),
+	}
+
+	go func() {
+		defer close(run.returned)
+
+		run.label <- labelRun()
+		run.errCh <- svc.Start(ctx, readyCh)
+	}()
+
+	return run
+}
+
+// wait for Start to return and Done, then check goroutine leaks.
+func (run *startRun) wait(t testing.TB, svc startstopper.Service) {
+	t.Helper()
+
+	timeout := time.After(TimeoutDefault)
+
+	select {
+	case <-run.returned:
+	case <-timeout:
+		t.Errorf("startstoppertest: Start has not returned after %s", TimeoutDefault)
+		return
+	}
+
+	select {
+	case <-svc.Done():
+	case <-timeout:
+		t.Errorf("startstoppertest: not done after %s", TimeoutDefault)
+		return
+	}
+
+	checkLeaks(t, <-run.label, TimeoutDefault)
+}
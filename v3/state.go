@@ -0,0 +1,227 @@
+package startstopper
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// State of StartStopper lifecycle.
+type State int
+
+const (
+	// StateIdle never started.
+	StateIdle State = iota
+	// StateStarting startFn is running.
+	StateStarting
+	// StateRunning started, graceful context is not done.
+	StateRunning
+	// StateStopping graceful context is done, waiting for cleanup.
+	StateStopping
+	// StateKilling kill context is done, waiting for cleanup.
+	StateKilling
+	// StateStopped cleanup is done.
+	StateStopped
+	// StatePaused running, paused by Pause.
+	StatePaused
+)
+
+var stateNames = map[State]string{
+	StateIdle:     "idle",
+	StateStarting: "starting",
+	StateRunning:  "running",
+	StateStopping: "stopping",
+	StateKilling:  "killing",
+	StateStopped:  "stopped",
+	StatePaused:   "paused",
+}
+
+// String ...
+func (state State) String() string {
+	if name, ok := stateNames[state]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Transition ...
+type Transition struct {
+	From State
+	To   State
+	At   time.Time // by clock of StartStopper, see SetClock
+}
+
+// State returns current lifecycle state.
+// Threadsafe, does not wait for startFn.
+func (startStopper *StartStopper) State() State {
+	return WithMutex1(&startStopper.stateMu, func() State {
+		return startStopper.state
+	})
+}
+
+// Subscribe to state transitions.
+// Every transition is delivered in order, slow readers do not block StartStopper.
+// Call returned func to unsubscribe, channel is closed then.
+func (startStopper *StartStopper) Subscribe() (<-chan Transition, func()) {
+	ch, _, unsubscribe := startStopper.subscribe()
+	return ch, unsubscribe
+}
+
+// WaitFor waits until state is reached.
+// Returns immediately if StartStopper is in state already.
+func (startStopper *StartStopper) WaitFor(ctx context.Context, state State) error {
+	ch, current, unsubscribe := startStopper.subscribe()
+	defer unsubscribe()
+
+	if current == state {
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case transition := <-ch:
+			if transition.To == state {
+				return nil
+			}
+		}
+	}
+}
+
+func (startStopper *StartStopper) subscribe() (<-chan Transition, State, func()) {
+	sub := &subscriber{
+		wake: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+		ch:   make(chan Transition),
+	}
+
+	current := WithMutex1(&startStopper.stateMu, func() State {
+		if startStopper.subscribers == nil {
+			startStopper.subscribers = make(map[*subscriber]struct{})
+		}
+		startStopper.subscribers[sub] = struct{}{}
+		return startStopper.state
+	})
+
+	go sub.forward()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			WithMutex(&startStopper.stateMu, func() {
+				delete(startStopper.subscribers, sub)
+			})
+			close(sub.stop)
+		})
+	}
+
+	return sub.ch, current, unsubscribe
+}
+
+// beginRun switches to StateStarting and returns new run generation.
+func (startStopper *StartStopper) beginRun() uint64 {
+	return WithMutex1(&startStopper.stateMu, func() uint64 {
+		startStopper.gen++
+		startStopper.transition(StateStarting)
+		return startStopper.gen
+	})
+}
+
+// setState of run gen, only if current state is one of from (any if empty).
+func (startStopper *StartStopper) setState(gen uint64, to State, from ...State) bool {
+	return WithMutex1(&startStopper.stateMu, func() bool {
+		if gen != startStopper.gen {
+			return false
+		}
+		if len(from) > 0 && !slices.Contains(from, startStopper.state) {
+			return false
+		}
+
+		startStopper.transition(to)
+		return true
+	})
+}
+
+// stateMu must be held already
+func (startStopper *StartStopper) transition(to State) {
+	if startStopper.state == to {
+		return
+	}
+
+	transition := Transition{
+		From: startStopper.state,
+		To:   to,
+		At:   startStopper.stateClock.Now(),
+	}
+	startStopper.state = to
+	if to == StateRunning {
+		startStopper.runningGen = startStopper.gen
+	}
+
+	for sub := range startStopper.subscribers {
+		sub.push(transition)
+	}
+}
+
+// started reports whether current (or last) run reached StateRunning.
+func (startStopper *StartStopper) started() bool {
+	return WithMutex1(&startStopper.stateMu, func() bool {
+		return startStopper.gen != 0 && startStopper.runningGen == startStopper.gen
+	})
+}
+
+// generation of current (or last) run.
+func (startStopper *StartStopper) generation() uint64 {
+	return WithMutex1(&startStopper.stateMu, func() uint64 {
+		return startStopper.gen
+	})
+}
+
+type subscriber struct {
+	mu    sync.Mutex
+	queue []Transition
+
+	wake chan struct{} // has queued transitions
+	stop chan struct{} // unsubscribed
+	ch   chan Transition
+}
+
+func (sub *subscriber) push(transition Transition) {
+	WithMutex(&sub.mu, func() {
+		sub.queue = append(sub.queue, transition)
+	})
+
+	select {
+	case sub.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (sub *subscriber) forward() {
+	defer close(sub.ch)
+
+	for {
+		queue := WithMutex1(&sub.mu, func() []Transition {
+			queue := sub.queue
+			sub.queue = nil
+			return queue
+		})
+
+		for _, transition := range queue {
+			select {
+			case sub.ch <- transition:
+			case <-sub.stop:
+				return
+			}
+		}
+
+		select {
+		case <-sub.wake:
+		case <-sub.stop:
+			return
+		}
+	}
+}
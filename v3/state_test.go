@@ -0,0 +1,159 @@
+package startstopper_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/Darigaaz/startstopper/v3/startstoppertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collectStates(ch <-chan startstopper.Transition, n int) []startstopper.State {
+	var states []startstopper.State
+	for i := 0; i < n; i++ {
+		transition := <-ch
+		if len(states) == 0 {
+			states = append(states, transition.From)
+		}
+		states = append(states, transition.To)
+	}
+	return states
+}
+
+func TestStartStopper_State(t *testing.T) {
+	t.Run("graceful", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+		assert.Equal(t, startstopper.StateIdle, startStopper.State())
+
+		transitions, unsubscribe := startStopper.Subscribe()
+		t.Cleanup(unsubscribe)
+
+		var stateInStartFn startstopper.State
+		startFn := func() error {
+			stateInStartFn = startStopper.State()
+			return nil
+		}
+
+		cleanupDone, doneFn := startstopper.ChanCloser(nil)
+
+		// SUT
+		ctx, _, done, err := startStopper.Start(t.Context(), cleanupDone, nil, startFn)
+		require.NoError(t, err)
+		assert.Equal(t, startstopper.StateStarting, stateInStartFn)
+		assert.Equal(t, startstopper.StateRunning, startStopper.State())
+
+		go func() {
+			<-ctx.Done()
+			doneFn()
+		}()
+
+		startStopper.CloseAsync()
+		<-done
+
+		assert.Equal(t, []startstopper.State{
+			startstopper.StateIdle,
+			startstopper.StateStarting,
+			startstopper.StateRunning,
+			startstopper.StateStopping,
+			startstopper.StateStopped,
+		}, collectStates(transitions, 4))
+		assert.Equal(t, startstopper.StateStopped, startStopper.State())
+	})
+
+	t.Run("kill timer", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), func(context.Context) time.Duration { return time.Millisecond })
+
+		transitions, unsubscribe := startStopper.Subscribe()
+		t.Cleanup(unsubscribe)
+
+		cleanupDone, doneFn := startstopper.ChanCloser(nil)
+
+		// SUT
+		_, killCtx, done, err := startStopper.Start(t.Context(), cleanupDone, nil, nil)
+		require.NoError(t, err)
+
+		go func() {
+			// ignore graceful shutdown
+			<-killCtx.Done()
+			doneFn()
+		}()
+
+		startStopper.CloseAsync()
+		<-done
+
+		assert.Equal(t, []startstopper.State{
+			startstopper.StateIdle,
+			startstopper.StateStarting,
+			startstopper.StateRunning,
+			startstopper.StateStopping,
+			startstopper.StateKilling,
+			startstopper.StateStopped,
+		}, collectStates(transitions, 5))
+	})
+
+	t.Run("failed startFn", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		transitions, unsubscribe := startStopper.Subscribe()
+		t.Cleanup(unsubscribe)
+
+		// SUT
+		_, _, _, err := startStopper.Start(t.Context(), nil, nil, func() error { return errors.New("fail") })
+		require.Error(t, err)
+
+		assert.Equal(t, []startstopper.State{
+			startstopper.StateIdle,
+			startstopper.StateStarting,
+			startstopper.StateStopped,
+		}, collectStates(transitions, 2))
+	})
+
+	t.Run("transition time by clock", func(t *testing.T) {
+		now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		startStopper := startstopper.New(t.Context(), nil)
+		startStopper.SetClock(startstoppertest.NewFakeClock(now))
+
+		transitions, unsubscribe := startStopper.Subscribe()
+		t.Cleanup(unsubscribe)
+
+		// SUT
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil, waitGraceful)
+		require.NoError(t, err)
+
+		transition := <-transitions
+		assert.Equal(t, startstopper.StateStarting, transition.To)
+		assert.Equal(t, now, transition.At)
+
+		startStopper.Close()
+		<-done
+	})
+}
+
+func TestStartStopper_WaitFor(t *testing.T) {
+	startStopper := startstopper.New(t.Context(), nil)
+
+	// SUT
+	require.NoError(t, startStopper.WaitFor(t.Context(), startstopper.StateIdle))
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, startStopper.WaitFor(ctx, startstopper.StateRunning), context.DeadlineExceeded)
+
+	waitErr := make(chan error, 1)
+	go func() {
+		waitErr <- startStopper.WaitFor(t.Context(), startstopper.StateStopped)
+	}()
+
+	cleanupDone, doneFn := startstopper.ChanCloser(nil)
+	_, _, _, err := startStopper.Start(t.Context(), cleanupDone, nil, nil)
+	require.NoError(t, err)
+
+	doneFn()
+	require.NoError(t, <-waitErr)
+	<-startStopper.Done()
+}
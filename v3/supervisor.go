@@ -0,0 +1,401 @@
+package startstopper
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+// Stopper is implemented by StartStopper.
+type Stopper interface {
+	Done() <-chan struct{}
+	CloseAsync()
+	KillAsync()
+}
+
+// Service is implemented by types embedding StartStopper (see Srv in example).
+// Start blocks until the run is done.
+type Service interface {
+	Stopper
+	Start(ctx context.Context, readyCh chan<- error) error
+}
+
+// Strategy defines which children are restarted when one of them exits.
+type Strategy int
+
+const (
+	// OneForOne restarts only the exited child.
+	OneForOne Strategy = iota
+	// OneForAll stops all other children and restarts all of them.
+	OneForAll
+	// RestForOne stops children started after the exited one and restarts them in order.
+	RestForOne
+)
+
+// ChildRestart defines when a child is restarted.
+type ChildRestart int
+
+const (
+	// Permanent child is always restarted.
+	Permanent ChildRestart = iota
+	// Transient child is restarted only if its Start returned error.
+	Transient
+	// Temporary child is never restarted.
+	Temporary
+)
+
+var (
+	// SupervisorMaxRestartsDefault ...
+	SupervisorMaxRestartsDefault = 3
+	// SupervisorPeriodDefault ...
+	SupervisorPeriodDefault = 5 * time.Second
+)
+
+var (
+	ErrMaxRestarts = errors.New("max restart intensity reached")
+	errMaxRestarts = NewErrorCode(ErrMaxRestarts, "STARTSTOPPER_ERR_MAX_RESTARTS")
+)
+
+// ChildSpec ...
+type ChildSpec struct {
+	Name    string
+	Service Service
+	Restart ChildRestart
+}
+
+// SupervisorSpec ...
+// Supervisor shuts down if more than MaxRestarts restarts happen within Period.
+type SupervisorSpec struct {
+	Strategy            Strategy
+	MaxRestarts         int           // zero means SupervisorMaxRestartsDefault
+	Period              time.Duration // zero means SupervisorPeriodDefault
+	KillTimeoutProvider func(ctx context.Context) time.Duration
+}
+
+// Supervisor starts children in order and restarts them according to Strategy.
+// Children are stopped in reverse order: CloseAsync first, KillAsync once the supervisor kill context is done.
+// Supervisor is a Service, so supervisors can be nested.
+type Supervisor struct {
+	StartStopper
+
+	spec     SupervisorSpec
+	children []*supervisedChild
+
+	// owned by the goroutine running Start
+	exits    chan childExit
+	pending  []childExit
+	restarts []time.Time
+}
+
+type supervisedChild struct {
+	ChildSpec
+
+	gen     int
+	running bool
+	wanted  bool // stopped by supervisor, must be started again
+}
+
+type childExit struct {
+	child *supervisedChild
+	gen   int
+	err   error
+}
+
+// NewSupervisor ...
+func NewSupervisor(
+	ctx context.Context,
+	spec SupervisorSpec,
+	children ...ChildSpec,
+) *Supervisor {
+	if spec.MaxRestarts == 0 {
+		spec.MaxRestarts = SupervisorMaxRestartsDefault
+	}
+	if spec.Period == 0 {
+		spec.Period = SupervisorPeriodDefault
+	}
+
+	sup := &Supervisor{
+		spec:  spec,
+		exits: make(chan childExit),
+	}
+	for _, child := range children {
+		sup.children = append(sup.children, &supervisedChild{ChildSpec: child})
+	}
+
+	_ = sup.StartStopper.Init(ctx, spec.KillTimeoutProvider)
+
+	return sup
+}
+
+// Start children in order and supervise them.
+// readyCh is notified once all children are started.
+// Blocks until all children are stopped.
+// Returns ErrMaxRestarts if restart intensity is exceeded.
+func (sup *Supervisor) Start(ctx context.Context, readyCh chan<- error) error {
+	err := sup.StartStopper.InitNotify(ctx, readyCh, nil)
+	if err != nil {
+		return err
+	}
+
+	cleanupErr, doneFn := ErrCloser(nil)
+
+	ctx, killCtx, done, err := sup.StartStopper.StartErr(ctx, cleanupErr, nil, nil)
+	if err != nil {
+		Notify(readyCh, err, NotifyCloseModeAlways)
+		return err
+	}
+
+	err = sup.supervise(ctx, killCtx, readyCh)
+
+	doneFn(err)
+	<-done
+
+	return err
+}
+
+func (sup *Supervisor) supervise(
+	ctx context.Context,
+	killCtx context.Context,
+	readyCh chan<- error,
+) error {
+	sup.pending = nil
+	sup.restarts = nil
+
+	for _, child := range sup.children {
+		err := sup.startChild(ctx, killCtx, child)
+		if err != nil {
+			sup.stopAll(killCtx)
+			Notify(readyCh, err, NotifyCloseModeAlways)
+			return err
+		}
+	}
+
+	Notify(readyCh, nil, NotifyCloseModeAlways)
+
+	ctxDone := ctx.Done()
+
+	for {
+		var ev childExit
+
+		if len(sup.pending) > 0 && ctx.Err() == nil {
+			ev, sup.pending = sup.pending[0], sup.pending[1:]
+		} else {
+			select {
+			case <-ctxDone:
+				sup.stopAll(killCtx)
+				return nil
+
+			case ev = <-sup.exits:
+				if !sup.exited(ev) {
+					continue
+				}
+			}
+		}
+
+		if ev.gen != ev.child.gen {
+			// child was restarted meanwhile
+			continue
+		}
+
+		err := sup.restart(ctx, killCtx, ev)
+		if err != nil {
+			sup.stopAll(killCtx)
+			return err
+		}
+	}
+}
+
+func (sup *Supervisor) restart(
+	ctx context.Context,
+	killCtx context.Context,
+	ev childExit,
+) error {
+	exited := ev.child
+
+	switch exited.Restart {
+	case Temporary:
+		return nil
+
+	case Transient:
+		if ev.err == nil {
+			return nil
+		}
+	}
+
+	if !sup.allowRestart() {
+		return JoinErrors(errMaxRestarts, ev.err)
+	}
+
+	set := []*supervisedChild{exited}
+	switch sup.spec.Strategy {
+	case OneForAll:
+		set = sup.children
+
+	case RestForOne:
+		set = sup.children[slices.Index(sup.children, exited):]
+	}
+
+	var restart []*supervisedChild
+	for _, child := range sup.children {
+		if child.wanted || child == exited || (child.running && slices.Contains(set, child)) {
+			restart = append(restart, child)
+		}
+	}
+
+	for i := len(restart) - 1; i >= 0; i-- {
+		if restart[i].running {
+			restart[i].wanted = true
+			sup.stopChild(killCtx, restart[i])
+		}
+	}
+
+	for _, child := range restart {
+		err := sup.startChild(ctx, killCtx, child)
+		if err != nil {
+			// retry on next iteration, counts against restart intensity
+			child.wanted = true
+			sup.pending = append(sup.pending, childExit{child: child, gen: child.gen, err: err})
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// allowRestart records restart if restart intensity is not exceeded.
+func (sup *Supervisor) allowRestart() bool {
+	now := sup.getClock().Now()
+
+	restarts := sup.restarts[:0]
+	for _, t := range sup.restarts {
+		if now.Sub(t) < sup.spec.Period {
+			restarts = append(restarts, t)
+		}
+	}
+
+	if len(restarts) >= sup.spec.MaxRestarts {
+		sup.restarts = restarts
+		return false
+	}
+
+	sup.restarts = append(restarts, now)
+	return true
+}
+
+func (sup *Supervisor) startChild(
+	ctx context.Context,
+	killCtx context.Context,
+	child *supervisedChild,
+) error {
+	readyCh := make(chan error, 1)
+
+	child.gen++
+	child.running = true
+	child.wanted = false
+
+	// children are stopped explicitly, in reverse order
+	childCtx := context.WithoutCancel(ctx)
+
+	gen := child.gen
+	go func() {
+		err := child.Service.Start(childCtx, readyCh)
+		<-child.Service.Done()
+
+		sup.exits <- childExit{child: child, gen: gen, err: err}
+	}()
+
+	ctxDone := ctx.Done()
+	killChan := killCtx.Done()
+
+	for {
+		select {
+		case err := <-readyCh:
+			if err != nil {
+				sup.await(killCtx, child)
+			}
+			return err
+
+		case <-ctxDone:
+			ctxDone = nil
+			child.Service.CloseAsync()
+
+		case <-killChan:
+			killChan = nil
+			sup.killAll()
+
+		case ev := <-sup.exits:
+			if !sup.exited(ev) {
+				continue
+			}
+			if ev.child != child {
+				sup.pending = append(sup.pending, ev)
+				continue
+			}
+			select {
+			case err := <-readyCh:
+				// ready and exited at once, started child is restarted
+				if err == nil {
+					sup.pending = append(sup.pending, ev)
+				}
+				return err
+			default:
+			}
+			if ev.err == nil {
+				// exited before ready, take it as failed start
+				ev.err = errStart
+			}
+			return ev.err
+		}
+	}
+}
+
+func (sup *Supervisor) stopChild(killCtx context.Context, child *supervisedChild) {
+	child.Service.CloseAsync()
+	sup.await(killCtx, child)
+}
+
+func (sup *Supervisor) stopAll(killCtx context.Context) {
+	for i := len(sup.children) - 1; i >= 0; i-- {
+		if sup.children[i].running {
+			sup.stopChild(killCtx, sup.children[i])
+		}
+	}
+}
+
+// await child exit, escalate to KillAsync when killCtx is done.
+// Exits of other children are kept pending.
+func (sup *Supervisor) await(killCtx context.Context, child *supervisedChild) {
+	killChan := killCtx.Done()
+
+	for child.running {
+		select {
+		case <-killChan:
+			killChan = nil
+			sup.killAll()
+
+		case ev := <-sup.exits:
+			if sup.exited(ev) && ev.child != child {
+				sup.pending = append(sup.pending, ev)
+			}
+		}
+	}
+}
+
+func (sup *Supervisor) killAll() {
+	for _, child := range sup.children {
+		if child.running {
+			child.Service.KillAsync()
+		}
+	}
+}
+
+// exited marks child as stopped, returns false for stale events.
+func (sup *Supervisor) exited(ev childExit) bool {
+	if ev.gen != ev.child.gen {
+		return false
+	}
+
+	ev.child.running = false
+	return true
+}
@@ -0,0 +1,287 @@
+package startstopper_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errChild = errors.New("child failed")
+
+type testChild struct {
+	startstopper.StartStopper
+
+	starts atomic.Int32
+	fail   chan error
+
+	// optional hooks
+	startFn func() error
+	stopFn  func()
+}
+
+func newTestChild(ctx context.Context) *testChild {
+	child := &testChild{fail: make(chan error)}
+	_ = child.StartStopper.Init(ctx, nil)
+	return child
+}
+
+func (child *testChild) Start(ctx context.Context, readyCh chan<- error) error {
+	err := child.StartStopper.InitNotify(ctx, readyCh, nil)
+	if err != nil {
+		return err
+	}
+
+	cleanupDone, doneFn := startstopper.ChanCloser(nil)
+
+	startFn := func() error {
+		if child.startFn != nil {
+			if err := child.startFn(); err != nil {
+				return err
+			}
+		}
+		child.starts.Add(1)
+		return nil
+	}
+
+	ctx, _, done, err := child.StartStopper.Start(ctx, cleanupDone, readyCh, startFn)
+	if err != nil {
+		return err
+	}
+
+	var runErr error
+	go func() {
+		defer doneFn()
+		if child.stopFn != nil {
+			defer child.stopFn()
+		}
+
+		select {
+		case <-ctx.Done():
+		case runErr = <-child.fail:
+		}
+	}()
+
+	<-done
+	return runErr
+}
+
+func startService(t *testing.T, svc startstopper.Service) <-chan error {
+	t.Helper()
+
+	readyCh := make(chan error, 1)
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- svc.Start(t.Context(), readyCh)
+	}()
+
+	require.NoError(t, <-readyCh)
+	return errCh
+}
+
+func startCounts(children ...*testChild) func() []int32 {
+	return func() []int32 {
+		counts := make([]int32, 0, len(children))
+		for _, child := range children {
+			counts = append(counts, child.starts.Load())
+		}
+		return counts
+	}
+}
+
+func TestSupervisor_Strategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy startstopper.Strategy
+		expected []int32
+	}{
+		{name: "OneForOne", strategy: startstopper.OneForOne, expected: []int32{1, 2, 1}},
+		{name: "OneForAll", strategy: startstopper.OneForAll, expected: []int32{2, 2, 2}},
+		{name: "RestForOne", strategy: startstopper.RestForOne, expected: []int32{1, 2, 2}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			children := []*testChild{newTestChild(t.Context()), newTestChild(t.Context()), newTestChild(t.Context())}
+
+			sup := startstopper.NewSupervisor(
+				t.Context(),
+				startstopper.SupervisorSpec{Strategy: tt.strategy},
+				startstopper.ChildSpec{Name: "a", Service: children[0]},
+				startstopper.ChildSpec{Name: "b", Service: children[1]},
+				startstopper.ChildSpec{Name: "c", Service: children[2]},
+			)
+
+			// SUT
+			errCh := startService(t, sup)
+			assert.Equal(t, []int32{1, 1, 1}, startCounts(children...)())
+
+			children[1].fail <- errChild
+
+			assert.Eventually(t, func() bool {
+				return assert.ObjectsAreEqual(tt.expected, startCounts(children...)())
+			}, time.Second, time.Millisecond)
+
+			sup.Close()
+			require.NoError(t, <-errCh)
+
+			for _, child := range children {
+				<-child.Done()
+			}
+		})
+	}
+}
+
+func TestSupervisor_ChildRestart(t *testing.T) {
+	permanent := newTestChild(t.Context())
+	transient := newTestChild(t.Context())
+	temporary := newTestChild(t.Context())
+
+	sup := startstopper.NewSupervisor(
+		t.Context(),
+		startstopper.SupervisorSpec{MaxRestarts: 10},
+		startstopper.ChildSpec{Name: "permanent", Service: permanent, Restart: startstopper.Permanent},
+		startstopper.ChildSpec{Name: "transient", Service: transient, Restart: startstopper.Transient},
+		startstopper.ChildSpec{Name: "temporary", Service: temporary, Restart: startstopper.Temporary},
+	)
+
+	// SUT
+	errCh := startService(t, sup)
+
+	permanent.fail <- nil
+	transient.fail <- errChild
+	temporary.fail <- errChild
+
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]int32{2, 2, 1}, startCounts(permanent, transient, temporary)())
+	}, time.Second, time.Millisecond)
+
+	// clean exit of transient child is final
+	transient.fail <- nil
+	<-transient.Done()
+
+	permanent.fail <- nil
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]int32{3, 2, 1}, startCounts(permanent, transient, temporary)())
+	}, time.Second, time.Millisecond)
+
+	sup.Close()
+	require.NoError(t, <-errCh)
+}
+
+func TestSupervisor_MaxRestarts(t *testing.T) {
+	child := newTestChild(t.Context())
+
+	sup := startstopper.NewSupervisor(
+		t.Context(),
+		startstopper.SupervisorSpec{MaxRestarts: 2, Period: time.Minute},
+		startstopper.ChildSpec{Name: "child", Service: child},
+	)
+
+	// SUT
+	errCh := startService(t, sup)
+
+	for i := 0; i < 3; i++ {
+		child.fail <- errChild
+	}
+
+	err := <-errCh
+	require.ErrorIs(t, err, startstopper.ErrMaxRestarts)
+	require.ErrorIs(t, err, errChild)
+
+	<-sup.Done()
+	<-child.Done()
+	assert.Equal(t, int32(3), child.starts.Load())
+	require.ErrorIs(t, sup.Err(), startstopper.ErrMaxRestarts)
+}
+
+func TestSupervisor_Nested(t *testing.T) {
+	leaf1 := newTestChild(t.Context())
+	leaf2 := newTestChild(t.Context())
+
+	inner := startstopper.NewSupervisor(
+		t.Context(),
+		startstopper.SupervisorSpec{},
+		startstopper.ChildSpec{Name: "leaf1", Service: leaf1},
+		startstopper.ChildSpec{Name: "leaf2", Service: leaf2},
+	)
+
+	root := startstopper.NewSupervisor(
+		t.Context(),
+		startstopper.SupervisorSpec{},
+		startstopper.ChildSpec{Name: "inner", Service: inner},
+	)
+
+	// SUT
+	errCh := startService(t, root)
+	assert.Equal(t, []int32{1, 1}, startCounts(leaf1, leaf2)())
+
+	// leaf failure is handled by inner supervisor
+	leaf2.fail <- errChild
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]int32{1, 2}, startCounts(leaf1, leaf2)())
+	}, time.Second, time.Millisecond)
+
+	root.Close()
+	require.NoError(t, <-errCh)
+
+	for _, done := range []<-chan struct{}{inner.Done(), leaf1.Done(), leaf2.Done()} {
+		select {
+		case <-done:
+		default:
+			t.Fatal("child is not done")
+		}
+	}
+}
+
+func TestSupervisor_StopWhileStarting(t *testing.T) {
+	tests := []struct {
+		name  string
+		kill  bool
+		cause error
+	}{
+		{name: "close", cause: startstopper.ErrClosed},
+		{name: "kill", kill: true, cause: startstopper.ErrKilled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := newTestChild(t.Context())
+			second := &startingChild{started: make(chan struct{}), kill: tt.kill}
+			_ = second.Init(t.Context(), func(context.Context) time.Duration { return time.Minute })
+
+			sup := startstopper.NewSupervisor(
+				t.Context(),
+				startstopper.SupervisorSpec{KillTimeoutProvider: func(context.Context) time.Duration { return time.Minute }},
+				startstopper.ChildSpec{Name: "first", Service: first},
+				startstopper.ChildSpec{Name: "second", Service: second},
+			)
+
+			readyCh := make(chan error, 1)
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- sup.Start(t.Context(), readyCh)
+			}()
+			assert.Eventually(t, func() bool { return second.State() == startstopper.StateRunning }, time.Second, time.Millisecond)
+
+			// SUT
+			if tt.kill {
+				sup.KillAsync()
+			} else {
+				sup.CloseAsync()
+			}
+
+			require.ErrorIs(t, <-readyCh, tt.cause)
+			require.ErrorIs(t, <-errCh, tt.cause)
+			assert.ErrorIs(t, second.Cause(), tt.cause)
+			assert.Equal(t, startstopper.StateStopped, first.State())
+		})
+	}
+}
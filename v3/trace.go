@@ -0,0 +1,144 @@
+package startstopper
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Span names of a run.
+const (
+	SpanRun     = "startstopper.run"     // whole run, parent of spans below
+	SpanStartFn = "startstopper.startFn" // startFn
+	SpanRunning = "startstopper.running" // from started to graceful shutdown begin
+	SpanDrain   = "startstopper.drain"   // from graceful shutdown begin to kill
+	SpanKill    = "startstopper.kill"    // from kill to done
+)
+
+// Tracer starts spans, see SpanRecorder and NoopTracer.
+// Adapt it to OpenTelemetry or any other tracing library.
+type Tracer interface {
+	// Start span as a child of span in ctx (if any), returns ctx with the span.
+	Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, Span)
+}
+
+// Span ...
+type Span interface {
+	SetAttributes(attrs ...slog.Attr)
+	RecordError(err error)
+	End()
+}
+
+// NoopTracer is the default Tracer.
+type NoopTracer struct{}
+
+// Start ...
+func (NoopTracer) Start(ctx context.Context, _ string, _ ...slog.Attr) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...slog.Attr) {}
+func (noopSpan) RecordError(error)          {}
+func (noopSpan) End()                       {}
+
+// SetTracer makes each run produce SpanRun with child spans.
+// Graceful and kill contexts carry SpanRun, worker spans are its children.
+// Nil means NoopTracer.
+// Takes effect on next Start.
+func (startStopper *StartStopper) SetTracer(tracer Tracer) {
+	WithMutex(&startStopper.mu, func() {
+		startStopper.tracer = tracer
+	})
+}
+
+// spans of a single run
+type runTracer struct {
+	tracer Tracer
+	ctx    context.Context // carries run span
+	run    Span
+
+	mu    sync.Mutex
+	phase int // index in runPhaseSpans, only moves forward
+	span  Span
+}
+
+var runPhaseSpans = []string{"", SpanRunning, SpanDrain, SpanKill}
+
+func newRunTracer(ctx context.Context, tracer Tracer, name string) *runTracer {
+	if tracer == nil {
+		tracer = NoopTracer{}
+	}
+
+	ctx, run := tracer.Start(ctx, SpanRun, slog.String("startstopper.name", name))
+
+	return &runTracer{
+		tracer: tracer,
+		ctx:    ctx,
+		run:    run,
+	}
+}
+
+func (t *runTracer) startFn(startFn func() error) error {
+	_, span := t.tracer.Start(t.ctx, SpanStartFn)
+	defer span.End()
+
+	err := startFn()
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
+
+// enter phase span, ends the previous one.
+func (t *runTracer) enter(name string, attrs ...slog.Attr) {
+	WithMutex(&t.mu, func() {
+		phase := 0
+		for i, spanName := range runPhaseSpans {
+			if spanName == name {
+				phase = i
+			}
+		}
+		if phase <= t.phase {
+			return
+		}
+
+		if t.span != nil {
+			t.span.End()
+		}
+		t.phase = phase
+		_, t.span = t.tracer.Start(t.ctx, name, attrs...)
+	})
+}
+
+// end run span.
+func (t *runTracer) end(cause error, err error) {
+	WithMutex(&t.mu, func() {
+		if t.span != nil {
+			t.span.End()
+		}
+		t.phase = len(runPhaseSpans)
+		t.span = nil
+	})
+
+	if cause != nil {
+		t.run.SetAttributes(causeAttr(cause))
+	}
+	if err != nil {
+		t.run.RecordError(err)
+	}
+	t.run.End()
+}
+
+func causeAttr(cause error) slog.Attr {
+	if cause == nil {
+		return slog.String("startstopper.cause", "")
+	}
+	return slog.String("startstopper.cause", cause.Error())
+}
+
+func killTimeoutAttr(timeout time.Duration) slog.Attr {
+	return slog.Duration("startstopper.kill_timeout", timeout)
+}
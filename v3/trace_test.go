@@ -0,0 +1,87 @@
+package startstopper_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartStopper_SetTracer(t *testing.T) {
+	t.Run("run spans", func(t *testing.T) {
+		recorder := startstopper.NewSpanRecorder()
+
+		startStopper := startstopper.New(t.Context(), func(context.Context) time.Duration { return time.Millisecond })
+		startStopper.SetLogger("db", nil)
+		startStopper.SetTracer(recorder)
+
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, func() error { return nil },
+			func(ctx context.Context, killCtx context.Context) error {
+				_, span := recorder.Start(ctx, "worker")
+				defer span.End()
+
+				<-killCtx.Done()
+				return nil
+			},
+		)
+		require.NoError(t, err)
+
+		// SUT
+		startStopper.Close()
+		<-done
+
+		spans := recorder.Spans()
+		byName := map[string]startstopper.RecordedSpan{}
+		for _, span := range spans {
+			assert.False(t, span.End.IsZero(), span.Name)
+			byName[span.Name] = span
+		}
+
+		run := byName[startstopper.SpanRun]
+		assert.Zero(t, run.ParentID)
+		assert.Equal(t, "db", run.Attrs["startstopper.name"].String())
+		assert.Contains(t, run.Attrs["startstopper.cause"].String(), "kill timeout")
+
+		for _, name := range []string{
+			startstopper.SpanStartFn,
+			startstopper.SpanRunning,
+			startstopper.SpanDrain,
+			startstopper.SpanKill,
+			"worker",
+		} {
+			require.Contains(t, byName, name)
+			assert.Equal(t, run.ID, byName[name].ParentID, name)
+		}
+
+		drain := byName[startstopper.SpanDrain]
+		assert.Equal(t, "closed", drain.Attrs["startstopper.cause"].String())
+		assert.Equal(t, time.Millisecond, drain.Attrs["startstopper.kill_timeout"].Duration())
+
+		assert.False(t, byName[startstopper.SpanRunning].End.After(drain.Start))
+		assert.False(t, drain.End.After(byName[startstopper.SpanKill].Start))
+	})
+
+	t.Run("startFn error", func(t *testing.T) {
+		recorder := startstopper.NewSpanRecorder()
+
+		startStopper := startstopper.New(t.Context(), nil)
+		startStopper.SetTracer(recorder)
+
+		errStart := errors.New("no connection")
+
+		// SUT
+		_, _, _, err := startStopper.StartWorkers(t.Context(), nil, func() error { return errStart })
+		require.ErrorIs(t, err, errStart)
+
+		spans := recorder.Spans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, startstopper.SpanRun, spans[0].Name)
+		assert.Equal(t, []error{errStart}, spans[0].Errors)
+		assert.Equal(t, startstopper.SpanStartFn, spans[1].Name)
+		assert.Equal(t, []error{errStart}, spans[1].Errors)
+	})
+}
@@ -0,0 +1,278 @@
+package startstopper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StuckWorker is a worker that has not returned after kill.
+type StuckWorker struct {
+	Name      string // function name
+	Goroutine uint64
+	Stack     string // stacks of the worker goroutine and goroutines it created
+}
+
+// StuckReport of a run which is not done after kill.
+type StuckReport struct {
+	KilledAt time.Time     // by clock of StartStopper, see SetClock
+	Elapsed  time.Duration // since KilledAt by the same clock
+	Workers  []StuckWorker
+	// CleanupPending is set if cleanup channel of Start or StartErr is not closed.
+	// Goroutines which should close it are not known, see SetStuckWatchdog.
+	CleanupPending bool
+}
+
+// String formats report with stacks.
+func (report StuckReport) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "startstopper: %d workers not returned %s after kill\n",
+		len(report.Workers), report.Elapsed.Round(time.Millisecond))
+
+	for _, worker := range report.Workers {
+		fmt.Fprintf(&b, "\n%s (goroutine %d):\n%s\n", worker.Name, worker.Goroutine, worker.Stack)
+	}
+
+	if report.CleanupPending {
+		fmt.Fprintf(&b, "\n%s\n", cleanupPendingMsg)
+	}
+
+	return b.String()
+}
+
+// StuckSink receives stuck reports.
+type StuckSink func(report StuckReport)
+
+// StuckSinkWriter writes formatted reports to w.
+func StuckSinkWriter(w io.Writer) StuckSink {
+	return func(report StuckReport) {
+		_, _ = io.WriteString(w, report.String())
+	}
+}
+
+// StuckSinkLogger prints formatted reports to logger.
+func StuckSinkLogger(logger *log.Logger) StuckSink {
+	return func(report StuckReport) {
+		logger.Print(report.String())
+	}
+}
+
+// StuckSinkSlog logs a warning per stuck worker to logger, see SetLogger.
+func StuckSinkSlog(logger *slog.Logger) StuckSink {
+	return func(report StuckReport) {
+		for _, worker := range report.Workers {
+			logger.LogAttrs(context.Background(), slog.LevelWarn, "worker not returned after kill",
+				slog.String("worker", worker.Name),
+				slog.Uint64("goroutine", worker.Goroutine),
+				slog.Duration("since_kill", report.Elapsed),
+				slog.String("stack", worker.Stack),
+			)
+		}
+
+		if report.CleanupPending {
+			logger.LogAttrs(context.Background(), slog.LevelWarn, cleanupPendingMsg,
+				slog.Duration("since_kill", report.Elapsed),
+			)
+		}
+	}
+}
+
+const cleanupPendingMsg = "cleanup channel is not closed after kill, goroutines closing it are not tracked"
+
+// SetStuckWatchdog reports workers which have not returned timeout after kill context is cancelled.
+// Only workers of StartWorkers and Go are reported with stacks. Goroutines closing cleanup channel
+// of Start or StartErr are not known, report only tells it is not closed, see StuckReport.CleanupPending.
+// Zero timeout or nil sink disables the watchdog.
+// Takes effect on next Start.
+func (startStopper *StartStopper) SetStuckWatchdog(timeout time.Duration, sink StuckSink) {
+	WithMutex(&startStopper.mu, func() {
+		startStopper.stuckTimeout = timeout
+		startStopper.stuckSink = sink
+	})
+}
+
+// watch group once killCtx is done.
+func (group *workerGroup) watch(clock Clock, timeout time.Duration, sink StuckSink) {
+	if timeout <= 0 || sink == nil {
+		return
+	}
+
+	context.AfterFunc(group.killCtx, func() {
+		killedAt := clock.Now()
+
+		clock.AfterFunc(timeout, func() {
+			select {
+			case <-group.done:
+				return
+			default:
+			}
+
+			if report := group.stuckReport(killedAt, clock.Now().Sub(killedAt)); len(report.Workers) > 0 || report.CleanupPending {
+				sink(report)
+			}
+		})
+	})
+}
+
+// running worker of a group
+type trackedWorker struct {
+	name      string
+	goroutine uint64
+}
+
+// cleanupState of cleanup channel of Start and StartErr
+type cleanupState int
+
+const (
+	cleanupNone    cleanupState = iota
+	cleanupPending              // waiting for cleanup channel
+	cleanupTracked              // closed by a tracked worker, see Run
+	cleanupDone
+)
+
+// setCleanup state, cleanupTracked is kept until done.
+func (group *workerGroup) setCleanup(state cleanupState) {
+	WithMutex(&group.mu, func() {
+		if group.cleanup == cleanupTracked && state == cleanupPending {
+			return
+		}
+		group.cleanup = state
+	})
+}
+
+// track calling goroutine as worker until returned func is called.
+func (group *workerGroup) track(name string) func() {
+	worker := &trackedWorker{
+		name:      name,
+		goroutine: goroutineID(),
+	}
+
+	WithMutex(&group.mu, func() {
+		group.running[worker] = struct{}{}
+	})
+
+	return func() {
+		WithMutex(&group.mu, func() {
+			delete(group.running, worker)
+		})
+	}
+}
+
+func (group *workerGroup) stuckReport(killedAt time.Time, elapsed time.Duration) StuckReport {
+	var (
+		workers []trackedWorker
+		cleanup cleanupState
+	)
+	WithMutex(&group.mu, func() {
+		for worker := range group.running {
+			workers = append(workers, *worker)
+		}
+		cleanup = group.cleanup
+	})
+
+	stacks := goroutineStacks()
+
+	report := StuckReport{KilledAt: killedAt, Elapsed: elapsed, CleanupPending: cleanup == cleanupPending}
+	for _, worker := range workers {
+		report.Workers = append(report.Workers, StuckWorker{
+			Name:      worker.name,
+			Goroutine: worker.goroutine,
+			Stack:     stacks.tree(worker.goroutine),
+		})
+	}
+
+	return report
+}
+
+func workerName(worker Worker) string {
+	if fn := runtime.FuncForPC(reflect.ValueOf(worker).Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return "worker"
+}
+
+func goroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+
+	// goroutine 42 [running]:
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i >= 0 {
+		buf = buf[:i]
+	}
+
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+	return id
+}
+
+// goroutine stacks by id
+type stackDump struct {
+	stacks  map[uint64]string
+	parents map[uint64]uint64
+}
+
+func goroutineStacks() stackDump {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	dump := stackDump{
+		stacks:  map[uint64]string{},
+		parents: map[uint64]uint64{},
+	}
+
+	for _, block := range strings.Split(strings.TrimSpace(string(buf)), "\n\n") {
+		var id uint64
+		if _, err := fmt.Sscanf(block, "goroutine %d ", &id); err != nil {
+			continue
+		}
+		dump.stacks[id] = block
+
+		// created by pkg.fn in goroutine 42
+		if i := strings.LastIndex(block, " in goroutine "); i >= 0 {
+			line := block[i+len(" in goroutine "):]
+			if j := strings.IndexByte(line, '\n'); j >= 0 {
+				line = line[:j]
+			}
+			if parent, err := strconv.ParseUint(line, 10, 64); err == nil {
+				dump.parents[id] = parent
+			}
+		}
+	}
+
+	return dump
+}
+
+// tree returns stack of goroutine and its descendants.
+func (dump stackDump) tree(id uint64) string {
+	stacks := []string{dump.stacks[id]}
+
+	ids := map[uint64]bool{id: true}
+	for changed := true; changed; {
+		changed = false
+		for child, parent := range dump.parents {
+			if ids[parent] && !ids[child] {
+				ids[child] = true
+				stacks = append(stacks, dump.stacks[child])
+				changed = true
+			}
+		}
+	}
+
+	return strings.Join(stacks, "\n\n")
+}
@@ -0,0 +1,157 @@
+package startstopper_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stuckWorker(release <-chan struct{}) startstopper.Worker {
+	return func(context.Context, context.Context) error {
+		childDone := make(chan struct{})
+		go func() {
+			defer close(childDone)
+			<-release
+		}()
+
+		<-release
+		<-childDone
+		return nil
+	}
+}
+
+func TestStartStopper_SetStuckWatchdog(t *testing.T) {
+	t.Run("reports stuck workers", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), func(context.Context) time.Duration { return time.Millisecond })
+
+		reports := make(chan startstopper.StuckReport, 1)
+		startStopper.SetStuckWatchdog(10*time.Millisecond, func(report startstopper.StuckReport) {
+			reports <- report
+		})
+
+		release := make(chan struct{})
+
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil,
+			waitGraceful,
+			stuckWorker(release),
+		)
+		require.NoError(t, err)
+
+		// SUT
+		startStopper.CloseAsync()
+
+		report := <-reports
+		close(release)
+		<-done
+
+		require.Len(t, report.Workers, 1)
+		worker := report.Workers[0]
+		assert.Contains(t, worker.Name, "stuckWorker")
+		assert.NotZero(t, worker.Goroutine)
+		assert.Contains(t, worker.Stack, "watchdog_test.go")
+		// worker goroutine and its child
+		assert.Equal(t, 1, strings.Count(worker.Stack, "\n\ngoroutine "))
+
+		assert.Contains(t, report.String(), "1 workers not returned")
+	})
+
+	t.Run("cleanup of Start", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), func(context.Context) time.Duration { return time.Millisecond })
+
+		reports := make(chan startstopper.StuckReport, 1)
+		startStopper.SetStuckWatchdog(10*time.Millisecond, func(report startstopper.StuckReport) {
+			reports <- report
+		})
+
+		cleanupDone, doneFn := startstopper.ChanCloser(nil)
+
+		_, _, done, err := startStopper.Start(t.Context(), cleanupDone, nil, nil)
+		require.NoError(t, err)
+
+		// SUT
+		startStopper.KillAsync()
+
+		report := <-reports
+		doneFn()
+		<-done
+
+		// goroutine waiting for cleanup channel is not the stuck one
+		assert.Empty(t, report.Workers)
+		assert.True(t, report.CleanupPending)
+		assert.Contains(t, report.String(), "cleanup channel is not closed")
+	})
+
+	t.Run("restart loop of Run", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), func(context.Context) time.Duration { return time.Millisecond })
+
+		reports := make(chan startstopper.StuckReport, 1)
+		startStopper.SetStuckWatchdog(10*time.Millisecond, func(report startstopper.StuckReport) {
+			reports <- report
+		})
+
+		release := make(chan struct{})
+		runDone := make(chan error, 1)
+		go func() {
+			runDone <- startStopper.Run(t.Context(), nil, nil, stuckWorker(release))
+		}()
+		require.Eventually(t, func() bool {
+			return startStopper.State() == startstopper.StateRunning
+		}, time.Second, time.Millisecond)
+
+		// SUT
+		startStopper.KillAsync()
+
+		report := <-reports
+		close(release)
+		<-runDone
+
+		require.Len(t, report.Workers, 1)
+		assert.Contains(t, report.Workers[0].Name, "stuckWorker")
+		assert.False(t, report.CleanupPending)
+	})
+
+	t.Run("slog sink", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+		// SUT
+		startstopper.StuckSinkSlog(logger)(startstopper.StuckReport{
+			KilledAt: time.Now(),
+			Elapsed:  time.Second,
+			Workers:  []startstopper.StuckWorker{{Name: "pkg.flush", Goroutine: 42, Stack: "goroutine 42 [chan receive]:"}},
+		})
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "WARN", record["level"])
+		assert.Equal(t, "pkg.flush", record["worker"])
+		assert.Equal(t, float64(42), record["goroutine"])
+		assert.Equal(t, "goroutine 42 [chan receive]:", record["stack"])
+		assert.EqualValues(t, time.Second, record["since_kill"])
+	})
+
+	t.Run("not reported when done", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), func(context.Context) time.Duration { return time.Millisecond })
+
+		startStopper.SetStuckWatchdog(10*time.Millisecond, func(startstopper.StuckReport) {
+			t.Error("unexpected report")
+		})
+
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil, waitGraceful)
+		require.NoError(t, err)
+
+		// SUT
+		startStopper.Kill()
+		<-done
+
+		time.Sleep(20 * time.Millisecond)
+	})
+}
@@ -0,0 +1,153 @@
+package startstopper
+
+import (
+	"context"
+	"sync"
+)
+
+// Worker runs until ctx (graceful) or killCtx is done.
+// Returned error is reported by Err.
+// Panic is recovered into PanicError and closes the run, see SetRepanic.
+type Worker func(ctx context.Context, killCtx context.Context) error
+
+// SetFailFast makes the first worker error close the run, error becomes the cause.
+// Takes effect on next Start.
+func (startStopper *StartStopper) SetFailFast(failFast bool) {
+	WithMutex(&startStopper.mu, func() {
+		startStopper.failFast = failFast
+	})
+}
+
+// StartWorkers like Start, but the run is done when the last worker returns.
+// More workers can be added with Go while the run is not done.
+// Worker errors are joined and reported by Err.
+func (startStopper *StartStopper) StartWorkers(
+	ctx context.Context,
+	readyCh chan<- error, // optional
+	startFn func() error, // optional
+	workers ...Worker,
+) (
+	context.Context, // graceful context
+	context.Context, // killCtx context
+	<-chan struct{}, // done chan
+	error,
+) {
+	return startStopper.start(ctx, nil, workers, readyCh, startFn, runWorkers, false)
+}
+
+// Go adds worker to the current run, Done waits for it.
+// Works with any Start variant.
+// Returns ErrStart if there is no run or the last worker has already returned.
+func (startStopper *StartStopper) Go(worker Worker) error {
+	group := WithMutex1(&startStopper.mu, func() *workerGroup {
+		if startStopper.workers == nil || !startStopper.workers.add() {
+			return nil
+		}
+		return startStopper.workers
+	})
+
+	if group == nil {
+		return errStart
+	}
+
+	group.launch(worker)
+	return nil
+}
+
+// workers of a single run
+type workerGroup struct {
+	ctx     context.Context
+	killCtx context.Context
+	onError func(err error) // optional, fail fast
+	onPanic func(err error) // optional, recovered worker panic
+
+	mu       sync.Mutex
+	active   int  // starts with 1, held until workers of Start are launched
+	closed   bool // active reached zero
+	errs     []error
+	panicErr *PanicError // first recovered panic
+	running  map[*trackedWorker]struct{}
+	cleanup  cleanupState
+	done     chan struct{}
+}
+
+func newWorkerGroup(ctx context.Context, killCtx context.Context) *workerGroup {
+	return &workerGroup{
+		ctx:     ctx,
+		killCtx: killCtx,
+		active:  1,
+		running: map[*trackedWorker]struct{}{},
+		done:    make(chan struct{}),
+	}
+}
+
+// add worker, false if group is closed.
+func (group *workerGroup) add() bool {
+	return WithMutex1(&group.mu, func() bool {
+		if group.closed {
+			return false
+		}
+		group.active++
+		return true
+	})
+}
+
+// launch added worker, panic is recovered into error.
+func (group *workerGroup) launch(worker Worker) {
+	go func() {
+		untrack := group.track(workerName(worker))
+		defer untrack()
+
+		err, panicErr := callWorker(worker, group.ctx, group.killCtx)
+		if panicErr != nil {
+			group.recovered(err, panicErr)
+		}
+		group.finish(err, true)
+	}()
+}
+
+func (group *workerGroup) recovered(err error, panicErr *PanicError) {
+	WithMutex(&group.mu, func() {
+		if group.panicErr == nil {
+			group.panicErr = panicErr
+		}
+	})
+
+	if group.onPanic != nil {
+		group.onPanic(err)
+	}
+}
+
+// panicked returns first recovered panic, valid after done.
+func (group *workerGroup) panicked() *PanicError {
+	return WithMutex1(&group.mu, func() *PanicError {
+		return group.panicErr
+	})
+}
+
+func (group *workerGroup) finish(err error, failFast bool) {
+	if err != nil && failFast && group.onError != nil {
+		group.onError(err)
+	}
+
+	WithMutex(&group.mu, func() {
+		if err != nil {
+			group.errs = append(group.errs, err)
+		}
+
+		group.active--
+		if group.active == 0 {
+			group.closed = true
+			close(group.done)
+		}
+	})
+}
+
+// wait for all workers, returns joined errors.
+func (group *workerGroup) wait() error {
+	<-group.done
+
+	return WithMutex1(&group.mu, func() error {
+		return JoinErrors(group.errs...)
+	})
+}
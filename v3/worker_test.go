@@ -0,0 +1,118 @@
+package startstopper_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Darigaaz/startstopper/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func waitGraceful(ctx context.Context, _ context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+func TestStartStopper_StartWorkers(t *testing.T) {
+	t.Run("done after last worker", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		release := make(chan struct{})
+		worker := func(context.Context, context.Context) error {
+			<-release
+			return nil
+		}
+
+		// SUT
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil, worker)
+		require.NoError(t, err)
+
+		extraRelease := make(chan struct{})
+		require.NoError(t, startStopper.Go(func(context.Context, context.Context) error {
+			<-extraRelease
+			return nil
+		}))
+
+		close(release)
+
+		select {
+		case <-done:
+			t.Fatal("done before extra worker returned")
+		default:
+		}
+
+		close(extraRelease)
+		<-done
+
+		require.NoError(t, startStopper.Err())
+		require.ErrorIs(t, startStopper.Go(waitGraceful), startstopper.ErrStart)
+	})
+
+	t.Run("errors are joined", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		errA := errors.New("a")
+		errB := errors.New("b")
+
+		// SUT
+		_, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil,
+			func(context.Context, context.Context) error { return errA },
+			func(context.Context, context.Context) error { return errB },
+			waitGraceful,
+		)
+		require.NoError(t, err)
+
+		// not fail fast, run continues
+		assert.Equal(t, startstopper.StateRunning, startStopper.State())
+
+		err = startStopper.CloseErr()
+		<-done
+
+		require.ErrorIs(t, err, errA)
+		require.ErrorIs(t, err, errB)
+		require.ErrorIs(t, startStopper.Cause(), startstopper.ErrClosed)
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+		startStopper.SetFailFast(true)
+
+		errWorker := errors.New("worker failed")
+
+		// SUT
+		ctx, _, done, err := startStopper.StartWorkers(t.Context(), nil, nil,
+			waitGraceful,
+			func(context.Context, context.Context) error { return errWorker },
+		)
+		require.NoError(t, err)
+
+		<-done
+		require.ErrorIs(t, context.Cause(ctx), errWorker)
+		require.ErrorIs(t, startStopper.Cause(), errWorker)
+		require.ErrorIs(t, startStopper.Err(), errWorker)
+	})
+
+	t.Run("Go with Start", func(t *testing.T) {
+		startStopper := startstopper.New(t.Context(), nil)
+
+		cleanupDone, doneFn := startstopper.ChanCloser(nil)
+
+		_, _, done, err := startStopper.Start(t.Context(), cleanupDone, nil, nil)
+		require.NoError(t, err)
+
+		// SUT
+		require.NoError(t, startStopper.Go(waitGraceful))
+
+		doneFn()
+
+		select {
+		case <-done:
+			t.Fatal("done before worker returned")
+		default:
+		}
+
+		startStopper.Close()
+	})
+}